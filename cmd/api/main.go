@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -26,18 +28,69 @@ func main() {
 			log.Fatalf("failed to close database connection: %v", err)
 		}
 	}(db)
-	// Initialize spacex client.
-	externalClient := external.NewSpaceXAPIClient("https://api.spacexdata.com/v4/")
-	// Initialize the booking service with the spacex external client.
-	bookingService := service.NewBookingService(externalClient, db)
-	// Initialize the handler with the booking service.
-	handler := api.NewHandler(bookingService)
+	// Initialize the launch provider, the spacex client unless LAUNCH_PROVIDER=fake selects
+	// the in-memory fake without any launches for local development. SPACEX_API_URL overrides
+	// the base URL of the SpaceX API, e.g. to use the fakespacex server.
+	var launchProvider external.LaunchProvider = external.NewSpaceXAPIClientWithConfig(os.Getenv("SPACEX_API_URL"), spaceXConfig())
+	if os.Getenv("LAUNCH_PROVIDER") == "fake" {
+		log.Println("Using the in-memory fake launch provider.")
+		launchProvider = external.NewFakeLaunchProvider()
+	}
+	// Cache the launch and launchpad lookups in memory, or in the database with LAUNCH_CACHE=postgres.
+	// LAUNCH_CACHE=off disables the cache.
+	launchCache := newLaunchCache(launchProvider, db)
+	if launchCache != nil {
+		launchProvider = launchCache
+	}
+	// Register the SpaceX launch provider and the static-file providers of the other launch operators
+	// listed in STATIC_LAUNCH_PROVIDERS as name=path pairs, e.g. "acme=/etc/launches/acme.json".
+	providers := external.NewRegistry(external.SpaceXProvider)
+	if err := providers.Register(external.SpaceXProvider, launchProvider); err != nil {
+		log.Fatalf("failed to register launch provider: %v", err)
+	}
+	if err := providers.RegisterStaticFiles(os.Getenv("STATIC_LAUNCH_PROVIDERS")); err != nil {
+		log.Fatalf("failed to register static launch providers: %v", err)
+	}
+	log.Printf("Using launch providers %v.", providers.Names())
+	// Initialize the booking service with the launch providers and the policy applied while one is unavailable.
+	bookingService := service.NewBookingService(providers, degradePolicy(), db)
+	// Initialize the flight and schedule services.
+	flightService := service.NewFlightService(db)
+	scheduleService := service.NewScheduleService(providers, db)
+	launchCacheService := service.NewLaunchCacheService(launchCache)
+	launchpadService := service.NewLaunchpadService(db)
+	// Initialize the handler with the services.
+	handler := api.NewHandler(bookingService, flightService, scheduleService, launchCacheService, launchpadService)
 
 	router := gin.Default()
 	v1 := router.Group("/api/v1")
 	v1.POST("/bookings", handler.CreateBooking)
 	v1.GET("/bookings", handler.GetBookings)
-	v1.DELETE("/bookings/:id", handler.DeleteBooking)
+	v1.GET("/bookings/:id", handler.GetBooking)
+	v1.PUT("/bookings/:id", handler.UpdateBooking)
+	v1.PATCH("/bookings/:id", handler.PatchBooking)
+	v1.DELETE("/bookings/:id", handler.CancelBooking)
+	v1.POST("/bookings/:id/confirm", handler.ConfirmBooking)
+	v1.POST("/bookings/:id/cancel", handler.CancelBooking)
+	v1.POST("/bookings/:id/flown", handler.MarkFlown)
+	v1.GET("/seats", handler.GetSeatAvailability)
+	v1.GET("/availability", handler.GetAvailability)
+	v1.GET("/flights", handler.GetFlights)
+	v1.GET("/flights/:id", handler.GetFlight)
+	v1.GET("/schedules", handler.GetSchedules)
+	v1.GET("/schedules/:id", handler.GetSchedule)
+	v1.GET("/schedule-overrides", handler.GetScheduleOverrides)
+	v1.GET("/launchpads", handler.GetLaunchpads)
+
+	// Admin endpoints require the ADMIN_TOKEN bearer token and are disabled when it is not set.
+	admin := v1.Group("", api.AdminOnly(os.Getenv("ADMIN_TOKEN")))
+	admin.POST("/schedules", handler.CreateSchedule)
+	admin.PUT("/schedules/:id", handler.UpdateSchedule)
+	admin.DELETE("/schedules/:id", handler.DeleteSchedule)
+	admin.POST("/schedule-overrides", handler.CreateScheduleOverride)
+	admin.DELETE("/schedule-overrides/:id", handler.DeleteScheduleOverride)
+	admin.GET("/launch-cache", handler.GetLaunchCacheStats)
+	admin.DELETE("/launch-cache", handler.InvalidateLaunchCache)
 
 	log.Println("API server listening on port 8080...")
 	err = router.Run(":8080")
@@ -45,3 +98,76 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// spaceXConfig returns the default SpaceX client settings overridden by the SPACEX_TIMEOUT, SPACEX_MAX_RETRIES,
+// SPACEX_RETRY_BACKOFF, SPACEX_BREAKER_THRESHOLD, SPACEX_BREAKER_COOLDOWN and SPACEX_MAX_PAGES environment variables.
+func spaceXConfig() external.ClientConfig {
+	config := external.DefaultClientConfig()
+	config.Timeout = envDuration("SPACEX_TIMEOUT", config.Timeout)
+	config.MaxRetries = envInt("SPACEX_MAX_RETRIES", config.MaxRetries)
+	config.RetryBackoff = envDuration("SPACEX_RETRY_BACKOFF", config.RetryBackoff)
+	config.BreakerThreshold = envInt("SPACEX_BREAKER_THRESHOLD", config.BreakerThreshold)
+	config.BreakerCooldown = envDuration("SPACEX_BREAKER_COOLDOWN", config.BreakerCooldown)
+	config.MaxPages = envInt("SPACEX_MAX_PAGES", config.MaxPages)
+
+	return config
+}
+
+// newLaunchCache wraps the launch provider in a cache whose backend is selected by LAUNCH_CACHE, memory by default,
+// keeping the entries for LAUNCH_CACHE_TTL. It returns nil when LAUNCH_CACHE is off.
+func newLaunchCache(launchProvider external.LaunchProvider, db *database.DB) *external.CachedLaunchProvider {
+	ttl := envDuration("LAUNCH_CACHE_TTL", 5*time.Minute)
+	switch backend := os.Getenv("LAUNCH_CACHE"); backend {
+	case "", "memory":
+		return external.NewCachedLaunchProvider(launchProvider, external.NewMemoryCache(), ttl)
+	case "postgres":
+		return external.NewCachedLaunchProvider(launchProvider, database.NewPostgresCache(db), ttl)
+	case "off":
+		return nil
+	default:
+		log.Fatalf("invalid LAUNCH_CACHE %q, expected memory, postgres or off", backend)
+		return nil
+	}
+}
+
+// degradePolicy returns the policy set by SPACEX_DEGRADE_POLICY, reject by default.
+func degradePolicy() service.DegradePolicy {
+	policy := service.DegradePolicy(os.Getenv("SPACEX_DEGRADE_POLICY"))
+	switch policy {
+	case "":
+		return service.DegradeReject
+	case service.DegradeReject, service.DegradeAllow:
+		return policy
+	default:
+		log.Fatalf("invalid SPACEX_DEGRADE_POLICY %q, expected reject or allow", policy)
+		return ""
+	}
+}
+
+// envDuration returns the duration of the environment variable, such as "5s", or fallback when it is not set.
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Fatalf("invalid %s %q, expected a duration such as 5s", name, value)
+	}
+
+	return duration
+}
+
+// envInt returns the non-negative integer of the environment variable, or fallback when it is not set.
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		log.Fatalf("invalid %s %q, expected a non-negative integer", name, value)
+	}
+
+	return number
+}
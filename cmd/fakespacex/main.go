@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/fakespacex"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "Address to listen on.")
+	fixturesDir := flag.String("fixtures", "", "Directory with launchpads.json and launches.json. Defaults to the bundled fixtures.")
+	flag.Parse()
+
+	fixtures := fakespacex.DefaultFixtures()
+	if *fixturesDir != "" {
+		var err error
+		fixtures, err = fakespacex.LoadFixtures(*fixturesDir)
+		if err != nil {
+			log.Fatalf("failed to load fixtures: %v", err)
+		}
+	}
+	log.Printf("Serving %d launchpads and %d launches.", len(fixtures.Launchpads), len(fixtures.Launches))
+
+	log.Printf("Fake SpaceX API listening on %s, base URL http://localhost%s/v4/ ...", *addr, *addr)
+	if err := http.ListenAndServe(*addr, fakespacex.NewHandler(fixtures)); err != nil {
+		log.Fatal(err)
+	}
+}
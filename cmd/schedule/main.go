@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -13,6 +14,47 @@ import (
 )
 
 func main() {
+	// The override subcommand manages date-specific overrides instead of generating schedules.
+	if len(os.Args) > 1 && os.Args[1] == "override" {
+		runOverride(os.Args[2:])
+		return
+	}
+
+	capacity := flag.Int("capacity", models.DefaultFlightCapacity, "number of seats on each scheduled flight")
+	launchTime := flag.String("launch-time", models.DefaultLaunchTime, "UTC launch time (HH:MM) of the generated departures")
+	flightDays := flag.Int("flight-days", 90, "number of days, starting today, to create flights for from the schedules")
+	strategy := flag.String("strategy", strategyOptimize, "optimize: cover every destination every day and weight by demand; shuffle: shuffle destinations per launchpad")
+	demandDays := flag.Int("demand-days", 90, "number of past days of bookings used to weight destinations by demand, 0 disables weighting")
+	seed := flag.Int64("seed", 0, "seed of the shuffle strategy, combined with each launchpad ID")
+	mode := flag.String("mode", modeFill, "fill: keep existing schedules and only add missing launchpad days; replace: replace existing schedules and end those of inactive launchpads")
+	dryRun := flag.Bool("dry-run", false, "print the difference between the current and the proposed schedules without changing the database")
+	provider := flag.String("provider", external.SpaceXProvider, "launch provider whose active launchpads get schedules, spacex or a provider of STATIC_LAUNCH_PROVIDERS")
+	effectiveFrom := flag.String("effective-from", time.Now().UTC().Format(time.DateOnly), "UTC date (YYYY-MM-DD) from which the proposed schedules apply, earlier dates keep their schedule versions")
+	flag.Parse()
+	if *strategy != strategyOptimize && *strategy != strategyShuffle {
+		log.Fatalf("unknown strategy %q, expected %q or %q", *strategy, strategyOptimize, strategyShuffle)
+	}
+	if *demandDays < 0 {
+		log.Fatalf("demand-days must not be negative, got %d", *demandDays)
+	}
+	if *mode != modeFill && *mode != modeReplace {
+		log.Fatalf("unknown mode %q, expected %q or %q", *mode, modeFill, modeReplace)
+	}
+	if *capacity <= 0 {
+		log.Fatalf("capacity must be positive, got %d", *capacity)
+	}
+	if *flightDays < 0 {
+		log.Fatalf("flight-days must not be negative, got %d", *flightDays)
+	}
+	parsedLaunchTime, err := time.Parse("15:04", *launchTime)
+	if err != nil {
+		log.Fatalf("invalid launch-time %q, expected HH:MM", *launchTime)
+	}
+	from := parseDate("effective-from", *effectiveFrom)
+	if from.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		log.Fatalf("effective-from must not be in the past, got %s", *effectiveFrom)
+	}
+
 	log.Println("Initiating the schedule setup process for launchpads...")
 
 	databaseURL := os.Getenv("DATABASE_URL")
@@ -27,64 +69,213 @@ func main() {
 		}
 	}(db)
 
-	externalClient := external.NewSpaceXAPIClient("https://api.spacexdata.com/v4/")
+	// Register the launch providers the same way as the API server does.
+	providers := external.NewRegistry(external.SpaceXProvider)
+	if err := providers.Register(external.SpaceXProvider, external.NewSpaceXAPIClient(os.Getenv("SPACEX_API_URL"))); err != nil {
+		log.Fatalf("failed to register launch provider: %v", err)
+	}
+	if err := providers.RegisterStaticFiles(os.Getenv("STATIC_LAUNCH_PROVIDERS")); err != nil {
+		log.Fatalf("failed to register static launch providers: %v", err)
+	}
+	launchProvider, err := providers.Get(*provider)
+	if err != nil {
+		log.Fatalf("invalid provider: %v, expected one of %v", err, providers.Names())
+	}
 
 	body := prepareRequestBody()
-	availableLaunchpads, err := externalClient.GetActiveLaunchpads(body)
+	availableLaunchpads, err := launchProvider.GetActiveLaunchpads(body)
 	if err != nil {
 		log.Fatalf("failed to fetch active launchpads: %v", err)
 	}
-	log.Println("Number of active launchpads: ", len(availableLaunchpads))
+	log.Printf("Number of active %s launchpads: %d", *provider, len(availableLaunchpads))
+
+	var schedules []models.Schedule
+	switch *strategy {
+	case strategyShuffle:
+		schedules = utils.GenerateSchedule(availableLaunchpads, *seed)
+	case strategyOptimize:
+		demand := map[models.Destination]int{}
+		if *demandDays > 0 {
+			demand, err = db.GetDestinationDemand(time.Now().AddDate(0, 0, -*demandDays))
+			if err != nil {
+				log.Fatalf("failed to fetch destination demand: %v", err)
+			}
+		}
+		schedules = utils.OptimizeSchedule(availableLaunchpads, demand)
+	}
+	for i := range schedules {
+		schedules[i].Provider = *provider
+		schedules[i].Capacity = *capacity
+		schedules[i].LaunchTime = parsedLaunchTime.Format("15:04")
+		schedules[i].ValidFrom = from
+	}
 
-	schedules := utils.GenerateSchedule(availableLaunchpads)
-	// Insert schedule into database
-	if err := insertSchedules(db, schedules); err != nil {
-		log.Fatalf("failed to insert schedules: %v", err)
+	// Only the schedules of the provider are compared, the launchpads of the other providers keep theirs.
+	current, err := db.GetSchedules(models.ScheduleFilter{At: from, Provider: *provider})
+	if err != nil {
+		log.Fatalf("failed to fetch current schedules: %v", err)
 	}
+	if *strategy == strategyOptimize && *mode == modeFill && len(current) > 0 {
+		log.Printf("WARNING: the fill mode keeps the %d existing schedules and only adds the optimized days of launchpads without one, "+
+			"so the daily coverage and demand weighting of the optimize strategy are not guaranteed. Use -mode=replace to apply them.", len(current))
+	}
+	proposed := proposeSchedules(current, schedules, *mode)
+	diff := utils.DiffSchedules(current, proposed)
+	printDiff(diff)
 
-	log.Println("Launchpads schedules successfully inserted into schedules table.")
-}
+	upcoming, err := db.GetUpcomingBookings(from)
+	if err != nil {
+		log.Fatalf("failed to fetch upcoming bookings: %v", err)
+	}
+	printAffectedBookings(utils.AffectedBookings(providerBookings(upcoming, *provider), proposed))
+
+	if *dryRun {
+		log.Println("Dry run, the schedules table was not changed.")
+		return
+	}
 
-// insertSchedules inserts a list of schedules into the database.
-func insertSchedules(db *database.DB, schedules []models.Schedule) error {
-	// launchpad_id can only have one schedule per day of the week
-	query := `
-		INSERT INTO schedules (launchpad_id, destination_id, day_of_week, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (launchpad_id, day_of_week) DO UPDATE
-		SET destination_id = EXCLUDED.destination_id,
-		    updated_at = EXCLUDED.updated_at;` // Update fields in case of conflict
-
-	for _, schedule := range schedules {
-		_, err := db.Exec(query,
-			schedule.LaunchpadID,
-			schedule.Destination,
-			schedule.DayOfWeek,
-			time.Now(), // created_at
-			time.Now(), // updated_at
-		)
+	// Apply the differences as new schedule versions, earlier versions stay for history.
+	if err := applyDiff(db, diff, from); err != nil {
+		log.Fatalf("failed to apply schedules: %v", err)
+	}
+
+	log.Printf("Launchpads schedules successfully applied from %s.", from.Format(time.DateOnly))
+
+	if *flightDays > 0 {
+		created, err := db.MaterializeFlights(time.Now(), *flightDays)
 		if err != nil {
-			return fmt.Errorf("failed to insert schedule: %w", err)
+			log.Fatalf("failed to create flights: %v", err)
+		}
+		log.Printf("Created %d flights for the next %d days.", created, *flightDays)
+	}
+}
+
+const (
+	// strategyOptimize covers every destination every day when possible and weights by demand.
+	strategyOptimize = "optimize"
+	// strategyShuffle shuffles the destinations of each launchpad independently.
+	strategyShuffle = "shuffle"
+)
+
+const (
+	// modeFill keeps existing schedules and only adds launchpad days without a schedule.
+	modeFill = "fill"
+	// modeReplace replaces existing schedules with the generated ones and ends
+	// the schedules of launchpads that are no longer active.
+	modeReplace = "replace"
+)
+
+// applyDiff applies the schedule differences from the given date on, all of them or none. Added schedules start
+// a new version, changed ones close the current version the day before and start a new one, and removed ones are closed.
+// Versions that only start on the given date are corrected in place, so reruns do not pile up versions.
+func applyDiff(db *database.DB, diff utils.ScheduleDiff, from time.Time) error {
+	var closeIDs []uint
+	var requests []models.ScheduleRequest
+	for _, schedule := range diff.Added {
+		requests = append(requests, scheduleRequest(schedule, from))
+	}
+	for _, change := range diff.Changed {
+		request := scheduleRequest(change.Proposed, from)
+		request.ValidTo = change.Current.ValidTo
+		closeIDs = append(closeIDs, change.Current.ID)
+		requests = append(requests, request)
+	}
+	for _, schedule := range diff.Removed {
+		closeIDs = append(closeIDs, schedule.ID)
+	}
+
+	return db.ReplaceSchedules(closeIDs, requests, from)
+}
+
+// scheduleRequest converts a generated schedule into a request for a version valid from the given date.
+func scheduleRequest(schedule models.Schedule, from time.Time) models.ScheduleRequest {
+	dayOfWeek := schedule.DayOfWeek
+
+	return models.ScheduleRequest{
+		LaunchpadID:   schedule.LaunchpadID,
+		Provider:      schedule.Provider,
+		DestinationID: schedule.Destination,
+		DayOfWeek:     &dayOfWeek,
+		LaunchTime:    schedule.LaunchTime,
+		Capacity:      schedule.Capacity,
+		ValidFrom:     from,
+	}
+}
+
+// proposeSchedules returns the schedules that will be effective after the generated ones are applied in the mode.
+func proposeSchedules(current, generated []models.Schedule, mode string) []models.Schedule {
+	if mode == modeReplace {
+		return generated
+	}
+
+	// A launchpad day is filled when it has at least one departure, whatever its launch time.
+	type launchpadDay struct {
+		launchpadID string
+		dayOfWeek   time.Weekday
+	}
+	proposed := append([]models.Schedule{}, current...)
+	existing := make(map[launchpadDay]bool, len(current))
+	for _, schedule := range current {
+		existing[launchpadDay{schedule.LaunchpadID, schedule.DayOfWeek}] = true
+	}
+	for _, schedule := range generated {
+		if !existing[launchpadDay{schedule.LaunchpadID, schedule.DayOfWeek}] {
+			proposed = append(proposed, schedule)
 		}
 	}
 
-	return nil
+	return proposed
 }
 
-// prepareRequestBody constructs a RequestBody for active launchpads.
-func prepareRequestBody() models.RequestBody {
-	options := models.Options{
-		Select: map[string]int{
-			"id": 1,
-		},
+// providerBookings returns the bookings on flights from the launchpads of the provider.
+func providerBookings(bookings []models.Booking, provider string) []models.Booking {
+	var filtered []models.Booking
+	for _, booking := range bookings {
+		if booking.Provider == provider {
+			filtered = append(filtered, booking)
+		}
 	}
 
-	body := models.RequestBody{
-		Query: map[string]interface{}{
-			"status": "active",
-		},
-		Options: options,
+	return filtered
+}
+
+// printDiff prints the added, changed and removed schedules.
+func printDiff(diff utils.ScheduleDiff) {
+	if diff.Empty() {
+		log.Println("Schedules are up to date, nothing to change.")
+		return
 	}
 
-	return body
+	log.Printf("Schedule changes: %d added, %d changed, %d removed.", len(diff.Added), len(diff.Changed), len(diff.Removed))
+	for _, schedule := range diff.Added {
+		fmt.Printf("+ %s %-9s %s %s (capacity %d)\n", schedule.LaunchpadID, schedule.DayOfWeek, schedule.LaunchTime,
+			utils.String(schedule.Destination), schedule.Capacity)
+	}
+	for _, change := range diff.Changed {
+		fmt.Printf("~ %s %-9s %s %s (capacity %d) -> %s (capacity %d)\n", change.Current.LaunchpadID, change.Current.DayOfWeek, change.Current.LaunchTime,
+			utils.String(change.Current.Destination), change.Current.Capacity,
+			utils.String(change.Proposed.Destination), change.Proposed.Capacity)
+	}
+	for _, schedule := range diff.Removed {
+		fmt.Printf("- %s %-9s %s %s (capacity %d)\n", schedule.LaunchpadID, schedule.DayOfWeek, schedule.LaunchTime,
+			utils.String(schedule.Destination), schedule.Capacity)
+	}
+}
+
+// printAffectedBookings prints the upcoming bookings whose launchpad would no longer fly to their destination.
+func printAffectedBookings(bookings []models.Booking) {
+	if len(bookings) == 0 {
+		return
+	}
+
+	log.Printf("WARNING: %d upcoming bookings are on a launchpad/destination/launch time combination that no longer exists:", len(bookings))
+	for _, booking := range bookings {
+		fmt.Printf("! booking %d: %s %s to %s from %s on %s at %s\n", booking.ID, booking.FirstName, booking.LastName,
+			utils.String(booking.DestinationID), booking.LaunchpadID, booking.LaunchDate.Format(time.DateOnly), booking.LaunchTime)
+	}
+}
+
+// prepareRequestBody constructs a RequestBody for active launchpads.
+func prepareRequestBody() models.RequestBody {
+	return external.NewQuery(external.Eq("status", "active")).Select("id").Body()
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-playground/validator"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
+	"github.com/klemis/go-spaceflight-booking-api/internal/service"
+	"github.com/klemis/go-spaceflight-booking-api/internal/utils"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+const overrideUsage = `usage:
+  schedule override list [-launchpad ID] [-from YYYY-MM-DD] [-to YYYY-MM-DD]
+  schedule override add -launchpad ID -date YYYY-MM-DD [-mode replace|add] [-destination N] [-launch-time HH:MM] [-capacity N] [-reason TEXT]
+  schedule override remove -id N
+
+A replace override replaces every weekly departure of the launchpad on the date with its departure,
+an add override adds a departure and keeps the weekly ones, except the one at the same launch time.
+A replace override without -destination closes the launchpad on the date.`
+
+// runOverride lists, adds or removes date-specific schedule overrides.
+func runOverride(args []string) {
+	if len(args) == 0 {
+		log.Fatal(overrideUsage)
+	}
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	db, err := database.InitDB(databaseURL)
+	if err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+	defer func(db *database.DB) {
+		err := db.Close()
+		if err != nil {
+			log.Fatalf("failed to close database connection: %v", err)
+		}
+	}(db)
+
+	// Overrides are managed through the schedule service like the API server does, so both apply the same
+	// defaults and validation. They do not depend on a launch provider, none is registered.
+	scheduleService := service.NewScheduleService(external.NewRegistry(external.SpaceXProvider), db)
+
+	switch args[0] {
+	case "list":
+		listOverrides(scheduleService, args[1:])
+	case "add":
+		addOverride(scheduleService, args[1:])
+	case "remove":
+		removeOverride(scheduleService, args[1:])
+	default:
+		log.Fatalf("unknown override command %q\n%s", args[0], overrideUsage)
+	}
+}
+
+func listOverrides(scheduleService service.ScheduleService, args []string) {
+	flags := flag.NewFlagSet("override list", flag.ExitOnError)
+	launchpadID := flags.String("launchpad", "", "only list overrides of this launchpad")
+	from := flags.String("from", "", "only list overrides on or after this date")
+	to := flags.String("to", "", "only list overrides on or before this date")
+	_ = flags.Parse(args)
+
+	filter := models.ScheduleOverrideFilter{
+		LaunchpadID: *launchpadID,
+		From:        parseDate("from", *from),
+		To:          parseDate("to", *to),
+	}
+	overrides, err := scheduleService.GetScheduleOverrides(filter)
+	if err != nil {
+		log.Fatalf("failed to fetch schedule overrides: %v", err)
+	}
+
+	for _, override := range overrides {
+		target := "closed"
+		if override.DestinationID != nil {
+			target = fmt.Sprintf("%s %s (capacity %d)", override.LaunchTime, utils.String(*override.DestinationID), override.Capacity)
+		}
+		fmt.Printf("%d\t%s\t%s\t%s\t%s\t%s\n", override.ID, override.Date.Format(time.DateOnly), override.LaunchpadID, override.Mode, target, override.Reason)
+	}
+}
+
+func addOverride(scheduleService service.ScheduleService, args []string) {
+	flags := flag.NewFlagSet("override add", flag.ExitOnError)
+	launchpadID := flags.String("launchpad", "", "launchpad ID")
+	date := flags.String("date", "", "date of the override")
+	mode := flags.String("mode", "", "replace (default): replace the weekly departures of the day; add: add a departure to them")
+	destination := flags.Uint("destination", 0, "destination ID, omit to close the launchpad")
+	launchTime := flags.String("launch-time", "", fmt.Sprintf("UTC launch time (HH:MM) of the flight (default %s)", models.DefaultLaunchTime))
+	capacity := flags.Int("capacity", 0, fmt.Sprintf("number of seats on the flight (default %d)", models.DefaultFlightCapacity))
+	reason := flags.String("reason", "", "reason of the override")
+	_ = flags.Parse(args)
+
+	if *launchpadID == "" || *date == "" {
+		log.Fatal(overrideUsage)
+	}
+
+	request := models.ScheduleOverrideRequest{
+		LaunchpadID: *launchpadID,
+		Date:        parseDate("date", *date),
+		Mode:        models.OverrideMode(*mode),
+		LaunchTime:  *launchTime,
+		Capacity:    *capacity,
+		Reason:      *reason,
+	}
+	if *destination != 0 {
+		destinationID := models.Destination(*destination)
+		request.DestinationID = &destinationID
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		log.Fatalf("invalid schedule override: %v", err)
+	}
+
+	override, err := scheduleService.CreateScheduleOverride(request)
+	if err != nil {
+		log.Fatalf("failed to add schedule override: %v", err)
+	}
+	log.Printf("Schedule override %d saved.", override.ID)
+}
+
+func removeOverride(scheduleService service.ScheduleService, args []string) {
+	flags := flag.NewFlagSet("override remove", flag.ExitOnError)
+	id := flags.Int("id", 0, "ID of the override")
+	_ = flags.Parse(args)
+
+	if *id == 0 {
+		log.Fatal(overrideUsage)
+	}
+
+	if err := scheduleService.DeleteScheduleOverride(*id); err != nil {
+		log.Fatalf("failed to remove schedule override: %v", err)
+	}
+	log.Printf("Schedule override %d removed.", *id)
+}
+
+// parseDate parses an optional YYYY-MM-DD flag value.
+func parseDate(name, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		log.Fatalf("invalid %s date %q, expected YYYY-MM-DD", name, value)
+	}
+
+	return date
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+func main() {
+	interval := flag.Duration("interval", 0, "time between synchronizations, 0 synchronizes once and exits")
+	flag.Parse()
+	if *interval < 0 {
+		log.Fatalf("interval must not be negative, got %s", *interval)
+	}
+
+	log.Println("Synchronizing SpaceX launchpads and launches...")
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	db, err := database.InitDB(databaseURL)
+	if err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+	defer func(db *database.DB) {
+		err := db.Close()
+		if err != nil {
+			log.Fatalf("failed to close database connection: %v", err)
+		}
+	}(db)
+
+	externalClient := external.NewSpaceXAPIClient(os.Getenv("SPACEX_API_URL"))
+
+	if *interval == 0 {
+		if err := synchronize(externalClient, db); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// A failed synchronization is retried at the next tick, the bookings keep using the previous data meanwhile.
+	for {
+		if err := synchronize(externalClient, db); err != nil {
+			log.Println(err)
+		}
+		time.Sleep(*interval)
+	}
+}
+
+// synchronize stores the launchpads and upcoming launches of SpaceX in the database.
+func synchronize(client *external.SpaceXAPIClient, db *database.DB) error {
+	syncedAt := time.Now()
+
+	launchpads, err := client.GetLaunchpads(prepareRequestBody())
+	if err != nil {
+		return fmt.Errorf("failed to fetch launchpads: %w", err)
+	}
+	if err := db.SyncLaunchpads(launchpads, syncedAt); err != nil {
+		return fmt.Errorf("failed to store launchpads: %w", err)
+	}
+	log.Println("Number of synchronized launchpads: ", len(launchpads))
+
+	launches, err := client.GetLaunches(prepareRequestBody(external.Eq("upcoming", true)))
+	if err != nil {
+		return fmt.Errorf("failed to fetch upcoming launches: %w", err)
+	}
+	if err := db.SyncLaunches(launches, syncedAt); err != nil {
+		return fmt.Errorf("failed to store upcoming launches: %w", err)
+	}
+	log.Println("Number of synchronized upcoming launches: ", len(launches))
+
+	return nil
+}
+
+// prepareRequestBody constructs a RequestBody returning every document matching the conditions at once.
+func prepareRequestBody(conditions ...external.Condition) models.RequestBody {
+	return external.NewQuery(conditions...).Pagination(false).Body()
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLaunchCacheStats handles the retrieval of the cache hit metrics of the SpaceX lookups.
+func (h *Handler) GetLaunchCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.LaunchCacheService.GetStats())
+}
+
+// InvalidateLaunchCache handles the removal of the cached SpaceX lookups of a launchpad, or of all of them.
+func (h *Handler) InvalidateLaunchCache(c *gin.Context) {
+	invalidated, err := h.LaunchCacheService.Invalidate(c.Query("launchpad_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not invalidate launch cache: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invalidated": invalidated})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// GetFlights handles the retrieval of a list of flights.
+func (h *Handler) GetFlights(c *gin.Context) {
+	var filter models.FlightFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	flights, err := h.FlightService.GetFlights(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve flights: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, flights)
+}
+
+// GetFlight handles the retrieval of a single flight.
+func (h *Handler) GetFlight(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	flight, err := h.FlightService.GetFlight(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Flight not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve flight: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, flight)
+}
@@ -3,24 +3,36 @@ package api
 import (
 	"database/sql"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator"
 
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
 	"github.com/klemis/go-spaceflight-booking-api/internal/service"
 	"github.com/klemis/go-spaceflight-booking-api/models"
 )
 
 type Handler struct {
-	BookingService service.BookingService
+	BookingService     service.BookingService
+	FlightService      service.FlightService
+	ScheduleService    service.ScheduleService
+	LaunchCacheService service.LaunchCacheService
+	LaunchpadService   service.LaunchpadService
 }
 
-// NewHandler creates a new Handler with the provided BookingService.
-func NewHandler(bookingService service.BookingService) *Handler {
+// NewHandler creates a new Handler with the provided services.
+func NewHandler(bookingService service.BookingService, flightService service.FlightService, scheduleService service.ScheduleService,
+	launchCacheService service.LaunchCacheService, launchpadService service.LaunchpadService) *Handler {
 	return &Handler{
-		BookingService: bookingService,
+		BookingService:     bookingService,
+		FlightService:      flightService,
+		ScheduleService:    scheduleService,
+		LaunchCacheService: launchCacheService,
+		LaunchpadService:   launchpadService,
 	}
 }
 
@@ -43,6 +55,19 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 
 	result, err := h.BookingService.CreateBooking(booking)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidLaunchTime) || errors.Is(err, external.ErrUnknownProvider) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if isBookingConflict(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not create booking: " + err.Error()})
+			return
+		}
+		if errors.Is(err, external.ErrUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Could not create booking: " + err.Error()})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create booking: " + err.Error()})
 		return
 	}
@@ -52,10 +77,24 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 
 // GetBookings handles the retrieval of a list of bookings.
 func (h *Handler) GetBookings(c *gin.Context) {
-	bookings, err := h.BookingService.GetBookings()
+	var filter models.BookingFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	bookings, err := h.BookingService.GetBookings(filter)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No bookings found"})
+		if errors.Is(err, database.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
 			return
 		}
 
@@ -66,8 +105,51 @@ func (h *Handler) GetBookings(c *gin.Context) {
 	c.JSON(http.StatusOK, bookings)
 }
 
-// DeleteBooking handles the deletion of a booking.
-func (h *Handler) DeleteBooking(c *gin.Context) {
+// GetBooking handles the retrieval of a single booking.
+func (h *Handler) GetBooking(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	booking, err := h.BookingService.GetBooking(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve booking: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// UpdateBooking handles the full replacement of an existing booking.
+func (h *Handler) UpdateBooking(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	var booking models.BookingRequest
+	if err := c.BindJSON(&booking); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	h.updateBooking(c, id, booking)
+}
+
+// PatchBooking handles the partial update of an existing booking.
+// Fields missing from the request body keep their current values.
+func (h *Handler) PatchBooking(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
@@ -75,16 +157,225 @@ func (h *Handler) DeleteBooking(c *gin.Context) {
 		return
 	}
 
-	err = h.BookingService.DeleteBooking(id)
+	existing, err := h.BookingService.GetBooking(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve booking: " + err.Error()})
+		return
+	}
+
+	booking := models.BookingRequest{
+		FirstName:     existing.FirstName,
+		LastName:      existing.LastName,
+		Gender:        existing.Gender,
+		Birthday:      existing.Birthday,
+		DestinationID: existing.DestinationID,
+		LaunchDate:    existing.LaunchDate,
+	}
+	if err := c.BindJSON(&booking); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	h.updateBooking(c, id, booking)
+}
+
+// updateBooking validates the merged request and passes it to the booking service.
+func (h *Handler) updateBooking(c *gin.Context, id int, booking models.BookingRequest) {
+	validate := validator.New()
+	err := validate.Struct(booking)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	result, err := h.BookingService.UpdateBooking(id, booking)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidLaunchTime) || errors.Is(err, external.ErrUnknownProvider) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrBookingClosed) || isBookingConflict(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not update booking: " + err.Error()})
+			return
+		}
+		if errors.Is(err, external.ErrUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Could not update booking: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update booking: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CancelBooking handles the cancellation of a booking. The booking is kept with the cancelled status.
+func (h *Handler) CancelBooking(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	// The request body with the cancellation reason is optional, an empty body cancels without a reason.
+	// Chunked requests have no content length, so the body is decoded whenever there is one.
+	var request models.CancelRequest
+	if c.Request.Body != nil {
+		if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			c.Abort()
+			return
+		}
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	result, err := h.BookingService.CancelBooking(id, request.Reason)
+	if err != nil {
+		respondTransitionError(c, err, "Could not cancel booking: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ConfirmBooking handles the confirmation of a pending booking.
+func (h *Handler) ConfirmBooking(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	result, err := h.BookingService.ConfirmBooking(id)
+	if err != nil {
+		respondTransitionError(c, err, "Could not confirm booking: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MarkFlown handles marking a confirmed booking as flown.
+func (h *Handler) MarkFlown(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	result, err := h.BookingService.MarkFlown(id)
+	if err != nil {
+		respondTransitionError(c, err, "Could not mark booking as flown: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// isBookingConflict reports whether the booking could not be placed on the requested flight.
+func isBookingConflict(err error) bool {
+	return errors.Is(err, database.ErrFlightFull) ||
+		errors.Is(err, database.ErrFlightCancelled) ||
+		errors.Is(err, database.ErrFlightRerouted) ||
+		errors.Is(err, service.ErrLaunchpadReserved) ||
+		errors.Is(err, service.ErrLaunchpadNotScheduled)
+}
+
+// respondTransitionError maps errors of booking status changes to HTTP responses.
+func respondTransitionError(c *gin.Context, err error, message string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": message + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message + err.Error()})
+}
+
+// GetSeatAvailability handles the lookup of remaining seats for a destination on a date.
+func (h *Handler) GetSeatAvailability(c *gin.Context) {
+	var request models.SeatAvailabilityRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	availability, err := h.BookingService.GetSeatAvailability(request.DestinationID, request.LaunchDate)
+	if err != nil {
+		if errors.Is(err, service.ErrNoDeparture) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No flight to the destination on this date"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve seat availability: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// GetAvailability handles the search for bookable dates to a destination.
+func (h *Handler) GetAvailability(c *gin.Context) {
+	var request models.AvailabilityRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	available, err := h.BookingService.GetAvailability(request.DestinationID, request.From, request.To)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+			return
+		}
+		if errors.Is(err, external.ErrUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Could not retrieve availability: " + err.Error()})
+			return
+		}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete booking: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve availability: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Booking deleted successfully"})
+	c.JSON(http.StatusOK, available)
 }
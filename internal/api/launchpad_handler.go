@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// GetLaunchpads handles the retrieval of the synchronized launchpads.
+func (h *Handler) GetLaunchpads(c *gin.Context) {
+	var filter models.LaunchpadFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	launchpads, err := h.LaunchpadService.GetLaunchpads(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve launchpads: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, launchpads)
+}
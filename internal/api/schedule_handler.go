@@ -0,0 +1,254 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
+	"github.com/klemis/go-spaceflight-booking-api/internal/service"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// GetSchedules handles the retrieval of schedules filtered by launchpad, destination or day of the week.
+func (h *Handler) GetSchedules(c *gin.Context) {
+	var filter models.ScheduleFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	schedules, err := h.ScheduleService.GetSchedules(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve schedules: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// GetSchedule handles the retrieval of a single schedule.
+func (h *Handler) GetSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	schedule, err := h.ScheduleService.GetSchedule(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CreateSchedule handles the creation of a new schedule.
+func (h *Handler) CreateSchedule(c *gin.Context) {
+	request, ok := bindScheduleRequest(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.ScheduleService.CreateSchedule(request)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) || errors.Is(err, service.ErrInvalidLaunchTime) ||
+			errors.Is(err, external.ErrUnknownProvider) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if errors.Is(err, database.ErrScheduleConflict) || errors.Is(err, database.ErrFlightBooked) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not create schedule: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateSchedule handles the replacement of an existing schedule.
+func (h *Handler) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	request, ok := bindScheduleRequest(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.ScheduleService.UpdateSchedule(id, request)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidDateRange) || errors.Is(err, service.ErrInvalidLaunchTime) ||
+			errors.Is(err, external.ErrUnknownProvider) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if errors.Is(err, database.ErrScheduleConflict) || errors.Is(err, database.ErrFlightBooked) ||
+			errors.Is(err, service.ErrScheduleApplied) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not update schedule: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule handles the deletion of a schedule.
+func (h *Handler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	err = h.ScheduleService.DeleteSchedule(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		if errors.Is(err, service.ErrScheduleApplied) || errors.Is(err, database.ErrFlightBooked) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not delete schedule: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
+
+// GetScheduleOverrides handles the retrieval of date-specific schedule overrides.
+func (h *Handler) GetScheduleOverrides(c *gin.Context) {
+	var filter models.ScheduleOverrideFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	overrides, err := h.ScheduleService.GetScheduleOverrides(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retrieve schedule overrides: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// CreateScheduleOverride handles the creation or replacement of a schedule override.
+func (h *Handler) CreateScheduleOverride(c *gin.Context) {
+	var request models.ScheduleOverrideRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	override, err := h.ScheduleService.CreateScheduleOverride(request)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLaunchTime) || errors.Is(err, service.ErrInvalidOverride) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if errors.Is(err, database.ErrOverrideConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not create schedule override: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create schedule override: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteScheduleOverride handles the deletion of a schedule override.
+func (h *Handler) DeleteScheduleOverride(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Abort()
+		return
+	}
+
+	err = h.ScheduleService.DeleteScheduleOverride(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule override not found"})
+			return
+		}
+		if errors.Is(err, database.ErrOverrideConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not delete schedule override: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete schedule override: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule override deleted successfully"})
+}
+
+// bindScheduleRequest decodes and validates the schedule request body.
+// It writes the error response and returns false when the body is invalid.
+func bindScheduleRequest(c *gin.Context) (models.ScheduleRequest, bool) {
+	var request models.ScheduleRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		c.Abort()
+		return request, false
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		c.Abort()
+		return request, false
+	}
+
+	return request, true
+}
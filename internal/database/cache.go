@@ -0,0 +1,81 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PostgresCache is a cache backend storing its entries in the launch_cache table,
+// shared by every API server using the database.
+type PostgresCache struct {
+	db *DB
+}
+
+// NewPostgresCache creates a PostgresCache on the database.
+func NewPostgresCache(db *DB) *PostgresCache {
+	return &PostgresCache{db: db}
+}
+
+// Get returns the value of the key, or false when it is missing or expired.
+func (c *PostgresCache) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	query := `SELECT value FROM launch_cache WHERE key = $1 AND expires_at > now();`
+	err := c.db.QueryRow(query, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to get cache entry: %w", err)
+	}
+
+	return value, true, nil
+}
+
+// Set stores the value of the key for the ttl and removes the expired entries.
+func (c *PostgresCache) Set(key string, value []byte, ttl time.Duration) error {
+	query := `
+        INSERT INTO launch_cache (key, value, expires_at) VALUES ($1, $2, now() + $3 * INTERVAL '1 millisecond')
+        ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`
+	if _, err := c.db.Exec(query, key, value, ttl.Milliseconds()); err != nil {
+		return fmt.Errorf("failed to set cache entry: %w", err)
+	}
+
+	if _, err := c.db.Exec(`DELETE FROM launch_cache WHERE expires_at <= now();`); err != nil {
+		return fmt.Errorf("failed to delete expired cache entries: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the entries whose key starts with the prefix and returns their number.
+func (c *PostgresCache) Delete(prefix string) (int, error) {
+	result, err := c.db.Exec(`DELETE FROM launch_cache WHERE left(key, length($1)) = $1;`, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cache entries: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+// DeleteKey removes the entry of the key and returns the number of removed entries.
+func (c *PostgresCache) DeleteKey(key string) (int, error) {
+	result, err := c.db.Exec(`DELETE FROM launch_cache WHERE key = $1;`, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/klemis/go-spaceflight-booking-api/models"
 	"log"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -13,11 +14,32 @@ import (
 
 // DBInterface defines the methods related to database operations.
 type DBInterface interface {
-	GetDestinationID(launchpadID string, launchDate time.Time) (models.Destination, error)
-	GetLaunchpadID(destinationID models.Destination, launchDate time.Time) (string, error)
-	InsertBooking(request models.BookingRequest, launchpadID string) (uint, error)
-	GetBookings() ([]models.Booking, error)
-	DeleteBooking(id int) error
+	GetLaunchpadDepartures(launchpadID string, launchDate time.Time) ([]models.Departure, error)
+	GetDepartures(destinationID models.Destination, launchDate time.Time) ([]models.Departure, error)
+	GetSchedules(filter models.ScheduleFilter) ([]models.Schedule, error)
+	GetSchedule(id int) (models.Schedule, error)
+	InsertSchedule(request models.ScheduleRequest) (models.Schedule, error)
+	UpdateSchedule(id int, request models.ScheduleRequest) (models.Schedule, error)
+	DeleteSchedule(id int) error
+	ReplaceSchedules(closeIDs []uint, requests []models.ScheduleRequest, validFrom time.Time) error
+	GetScheduleOverrides(filter models.ScheduleOverrideFilter) ([]models.ScheduleOverride, error)
+	InsertScheduleOverride(request models.ScheduleOverrideRequest) (models.ScheduleOverride, error)
+	DeleteScheduleOverride(id int) error
+	InsertBooking(request models.BookingRequest, departure models.Departure) (models.Booking, error)
+	UpdateBooking(id int, request models.BookingRequest, departure models.Departure) (models.Booking, error)
+	GetBookings(filter models.BookingFilter) (models.BookingPage, error)
+	GetBooking(id int) (models.Booking, error)
+	GetUpcomingBookings(from time.Time) ([]models.Booking, error)
+	GetDestinationDemand(since time.Time) (map[models.Destination]int, error)
+	UpdateBookingStatus(id int, from, to models.BookingStatus, reason string) error
+	GetFlights(filter models.FlightFilter) ([]models.Flight, error)
+	GetFlight(id int) (models.Flight, error)
+	MaterializeFlights(from time.Time, days int) (int, error)
+	SyncLaunchpads(launchpads []models.Launchpad, syncedAt time.Time) error
+	SyncLaunches(launches []models.Launch, syncedAt time.Time) error
+	GetLastSync(resource string) (time.Time, error)
+	GetLaunchpads(filter models.LaunchpadFilter) ([]models.LaunchpadSummary, error)
+	HasLaunch(launchpadID string, launchDate time.Time) (bool, error)
 }
 
 // DB is a wrapper around sql.DB that implements DBInterface.
@@ -30,12 +52,92 @@ func NewDB(db *sql.DB) *DB {
 	return &DB{DB: db}
 }
 
-func (db *DB) GetBookings() ([]models.Booking, error) {
-	var bookings []models.Booking
-	query := `SELECT id, first_name, last_name, gender, birthday, launchpad_id, destination_id, launch_date FROM bookings;`
-	rows, err := db.Query(query)
+// bookingColumns lists the bookings columns in the order expected by scanBooking.
+// The launch time is taken from the flight of the booking.
+const bookingColumns = `id, first_name, last_name, gender, birthday, launchpad_id, provider, destination_id, launch_date, status, COALESCE(cancellation_reason, ''), flight_id,
+    (SELECT to_char(f.launch_time, 'HH24:MI') FROM flights f WHERE f.id = flight_id)`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBooking scans a row selected with bookingColumns into a Booking.
+func scanBooking(row rowScanner) (models.Booking, error) {
+	var booking models.Booking
+	err := row.Scan(&booking.ID, &booking.FirstName, &booking.LastName, &booking.Gender, &booking.Birthday, &booking.LaunchpadID, &booking.Provider, &booking.DestinationID, &booking.LaunchDate, &booking.Status, &booking.CancelReason, &booking.FlightID, &booking.LaunchTime)
 	if err != nil {
-		return nil, err
+		return models.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// GetBookings returns a page of bookings matching the filter together with the total count of matching bookings.
+// No matching bookings is not an error and results in an empty page.
+func (db *DB) GetBookings(filter models.BookingFilter) (models.BookingPage, error) {
+	var where conditions
+	if filter.DestinationID != 0 {
+		where.add("destination_id = ?", filter.DestinationID)
+	}
+	if filter.LaunchpadID != "" {
+		where.add("launchpad_id = ?", filter.LaunchpadID)
+	}
+	if filter.Provider != "" {
+		where.add("provider = ?", filter.Provider)
+	}
+	if filter.Status != "" {
+		where.add("status = ?", filter.Status)
+	}
+	if !filter.LaunchDateFrom.IsZero() {
+		where.add("launch_date >= ?", filter.LaunchDateFrom)
+	}
+	if !filter.LaunchDateTo.IsZero() {
+		// The upper bound is inclusive, so bookings on the whole last day are matched.
+		where.add("launch_date < ?", filter.LaunchDateTo.AddDate(0, 0, 1))
+	}
+	if filter.Name != "" {
+		where.add("(first_name || ' ' || last_name) ILIKE ?", "%"+filter.Name+"%")
+	}
+
+	// An empty result is a valid page, so it is encoded as [] instead of null.
+	page := models.BookingPage{Bookings: []models.Booking{}}
+	countQuery := `SELECT COUNT(*) FROM bookings` + where.clause() + `;`
+	if err := db.QueryRow(countQuery, where.args...).Scan(&page.TotalCount); err != nil {
+		return page, err
+	}
+
+	descending := strings.HasPrefix(filter.Sort, "-")
+	byLaunchDate := strings.TrimPrefix(filter.Sort, "-") == "launch_date"
+	operator, direction := ">", "ASC"
+	if descending {
+		operator, direction = "<", "DESC"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return page, err
+		}
+		if byLaunchDate {
+			where.add("(launch_date, id) "+operator+" (?, ?)", cursor.LaunchDate, cursor.ID)
+		} else {
+			where.add("id "+operator+" ?", cursor.ID)
+		}
+	}
+
+	orderBy := "id " + direction
+	if byLaunchDate {
+		orderBy = "launch_date " + direction + ", id " + direction
+	}
+
+	// Fetch one extra row to find out whether there is a next page.
+	args := append(where.args, filter.Limit+1)
+	query := `SELECT ` + bookingColumns + ` FROM bookings` +
+		where.clause() + ` ORDER BY ` + orderBy + fmt.Sprintf(` LIMIT $%d;`, len(args))
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return page, err
 	}
 	defer func(rows *sql.Rows) {
 		err := rows.Close()
@@ -45,26 +147,107 @@ func (db *DB) GetBookings() ([]models.Booking, error) {
 	}(rows)
 
 	for rows.Next() {
-		var booking models.Booking
-		err = rows.Scan(&booking.ID, &booking.FirstName, &booking.LastName, &booking.Gender, &booking.Birthday, &booking.LaunchpadID, &booking.DestinationID, &booking.LaunchDate)
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return page, err
+		}
+		page.Bookings = append(page.Bookings, booking)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Bookings) > filter.Limit {
+		page.Bookings = page.Bookings[:filter.Limit]
+		page.NextCursor = encodeCursor(page.Bookings[filter.Limit-1])
+	}
+
+	return page, nil
+}
+
+func (db *DB) GetBooking(id int) (models.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1;`
+	row := db.QueryRow(query, id)
+
+	booking, err := scanBooking(row)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// GetUpcomingBookings returns the pending and confirmed bookings launching on or after from.
+func (db *DB) GetUpcomingBookings(from time.Time) ([]models.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE launch_date >= $1 AND status IN ('pending', 'confirmed') ORDER BY launch_date, id;`
+	rows, err := db.Query(query, from)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetUpcomingBookings query")
+		}
+	}(rows)
+
+	var bookings []models.Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
 		if err != nil {
 			return nil, err
 		}
 		bookings = append(bookings, booking)
 	}
-
-	if len(bookings) == 0 {
-		return nil, sql.ErrNoRows
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return bookings, nil
 }
 
-func (db *DB) DeleteBooking(id int) error {
-	query := `DELETE FROM bookings WHERE id = $1;`
-	result, err := db.Exec(query, id)
+// GetDestinationDemand counts the bookings per destination created since the given time.
+// Cancelled bookings are counted as well, since they still show interest in the destination.
+func (db *DB) GetDestinationDemand(since time.Time) (map[models.Destination]int, error) {
+	query := `SELECT destination_id, COUNT(*) FROM bookings WHERE created_at >= $1 GROUP BY destination_id;`
+	rows, err := db.Query(query, since)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetDestinationDemand query")
+		}
+	}(rows)
+
+	demand := make(map[models.Destination]int)
+	for rows.Next() {
+		var destination models.Destination
+		var count int
+		if err := rows.Scan(&destination, &count); err != nil {
+			return nil, err
+		}
+		demand[destination] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return demand, nil
+}
+
+// UpdateBookingStatus moves a booking from the given status to a new one.
+// It returns sql.ErrNoRows if the booking does not exist or is no longer in the expected status.
+func (db *DB) UpdateBookingStatus(id int, from, to models.BookingStatus, reason string) error {
+	query := `
+        UPDATE bookings
+        SET status = $1, cancellation_reason = NULLIF($2, ''), updated_at = CURRENT_TIMESTAMP
+        WHERE id = $3 AND status = $4`
+
+	result, err := db.Exec(query, to, reason, id, from)
+	if err != nil {
+		return fmt.Errorf("failed to update booking status: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -79,61 +262,151 @@ func (db *DB) DeleteBooking(id int) error {
 	return nil
 }
 
-func (db *DB) GetDestinationID(launchpadID string, launchDate time.Time) (models.Destination, error) {
-	query := `SELECT destination_id FROM schedules WHERE launchpad_id = $1 AND day_of_week = $2;`
-	row := db.QueryRow(query, launchpadID, launchDate.Weekday())
-
-	var schedule models.Schedule
-	err := row.Scan(&schedule.Destination)
+// GetLaunchpadDepartures returns every departure from the launchpad on the launch date, whatever its destination,
+// ordered by launch time. Only the launchpad, provider, destination, date and launch time of the departures are set.
+func (db *DB) GetLaunchpadDepartures(launchpadID string, launchDate time.Time) ([]models.Departure, error) {
+	query := `
+        SELECT provider, destination_id, to_char(launch_time, 'HH24:MI')
+        FROM effective_schedules($2)
+        WHERE launchpad_id = $1
+        ORDER BY launch_time;`
+	rows, err := db.Query(query, launchpadID, flightDay(launchDate))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return 0, fmt.Errorf("missing destination for the provided launchpad at this date")
+		return nil, fmt.Errorf("failed to fetch launchpad departures: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetLaunchpadDepartures query")
 		}
+	}(rows)
 
-		return 0, err
+	var departures []models.Departure
+	for rows.Next() {
+		departure := models.Departure{
+			LaunchpadID: launchpadID,
+			LaunchDate:  launchDate,
+		}
+		if err := rows.Scan(&departure.Provider, &departure.DestinationID, &departure.LaunchTime); err != nil {
+			return nil, err
+		}
+		departures = append(departures, departure)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return schedule.Destination, nil
+	return departures, nil
 }
 
-func (db *DB) GetLaunchpadID(destinationID models.Destination, launchDate time.Time) (string, error) {
-	query := `SELECT launchpad_id FROM schedules WHERE destination_id = $1 AND day_of_week = $2;`
-	row := db.QueryRow(query, destinationID, launchDate.Weekday())
+// InsertBooking reserves a seat on the flight of the departure and inserts the booking in a single transaction.
+// It returns ErrFlightFull if the flight has no free seat left.
+func (db *DB) InsertBooking(request models.BookingRequest, departure models.Departure) (models.Booking, error) {
+	query := `
+        INSERT INTO bookings (first_name, last_name, gender, birthday, launchpad_id, destination_id, launch_date, flight_id, provider)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING ` + bookingColumns
 
-	var schedule models.Schedule
-	err := row.Scan(&schedule.LaunchpadID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", fmt.Errorf("missing launchpad for the provided destination at this date")
+	var booking models.Booking
+	err := db.withTx(func(tx *sql.Tx) error {
+		flightID, err := reserveSeat(tx, departure, 0)
+		if err != nil {
+			return err
 		}
 
-		return "", err
+		booking, err = scanBooking(tx.QueryRow(query,
+			request.FirstName,
+			request.LastName,
+			request.Gender,
+			request.Birthday,
+			departure.LaunchpadID,
+			request.DestinationID,
+			request.LaunchDate,
+			flightID,
+			departure.Provider,
+		))
+		if err != nil {
+			return fmt.Errorf("failed to insert booking: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.Booking{}, err
 	}
 
-	return schedule.LaunchpadID, nil
+	return booking, nil
 }
 
-func (db *DB) InsertBooking(request models.BookingRequest, launchpadID string) (uint, error) {
+// UpdateBooking reserves a seat on the flight of the departure and updates the booking in a single transaction.
+// It returns ErrFlightFull if the flight has no free seat left.
+// It returns sql.ErrNoRows if the booking does not exist.
+func (db *DB) UpdateBooking(id int, request models.BookingRequest, departure models.Departure) (models.Booking, error) {
 	query := `
-        INSERT INTO bookings (first_name, last_name, gender, birthday, launchpad_id, destination_id, launch_date)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id`
-
-	var id uint
-	err := db.QueryRow(query,
-		request.FirstName,
-		request.LastName,
-		request.Gender,
-		request.Birthday,
-		launchpadID,
-		request.DestinationID,
-		request.LaunchDate,
-	).Scan(&id)
+        UPDATE bookings
+        SET first_name = $1, last_name = $2, gender = $3, birthday = $4, launchpad_id = $5, destination_id = $6, launch_date = $7,
+            flight_id = $8, provider = $9, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $10
+        RETURNING ` + bookingColumns
+
+	var booking models.Booking
+	err := db.withTx(func(tx *sql.Tx) error {
+		flightID, err := reserveSeat(tx, departure, id)
+		if err != nil {
+			return err
+		}
+
+		booking, err = scanBooking(tx.QueryRow(query,
+			request.FirstName,
+			request.LastName,
+			request.Gender,
+			request.Birthday,
+			departure.LaunchpadID,
+			request.DestinationID,
+			request.LaunchDate,
+			flightID,
+			departure.Provider,
+			id,
+		))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			return fmt.Errorf("failed to update booking: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to insert booking: %w", err)
+		return models.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// conditions collects the conditions of a WHERE clause together with their arguments.
+type conditions struct {
+	list []string
+	args []interface{}
+}
+
+// add appends a condition, replacing each ? placeholder with the positional parameter of the next value.
+func (c *conditions) add(condition string, values ...interface{}) {
+	for _, value := range values {
+		c.args = append(c.args, value)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.list = append(c.list, condition)
+}
+
+// clause joins the conditions into a WHERE clause.
+func (c *conditions) clause() string {
+	if len(c.list) == 0 {
+		return ""
 	}
 
-	return id, nil
+	return " WHERE " + strings.Join(c.list, " AND ")
 }
 
 // InitDB initializes the database connection.
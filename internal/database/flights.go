@@ -0,0 +1,243 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// ErrFlightCancelled is returned when a seat is requested on a flight that does not depart.
+var ErrFlightCancelled = errors.New("flight is not open for bookings")
+
+// ErrFlightRerouted is returned when the flight of a departure now flies to another destination.
+var ErrFlightRerouted = errors.New("flight flies to another destination")
+
+// ErrFlightBooked is returned when a schedule change would reroute or cancel a flight that already has bookings.
+var ErrFlightBooked = errors.New("flight already has bookings that would be rerouted or cancelled")
+
+// flightColumns lists the flights columns in the order expected by scanFlight.
+// The booked seats are counted from the active bookings of the flight.
+const flightColumns = `f.id, f.launchpad_id, f.provider, f.destination_id, f.launch_date, to_char(f.launch_time, 'HH24:MI'), f.status, f.capacity,
+    (SELECT COUNT(*) FROM bookings b WHERE b.flight_id = f.id AND b.status <> 'cancelled')`
+
+// scanFlight scans a row selected with flightColumns into a Flight.
+func scanFlight(row rowScanner) (models.Flight, error) {
+	var flight models.Flight
+	err := row.Scan(&flight.ID, &flight.LaunchpadID, &flight.Provider, &flight.DestinationID, &flight.LaunchDate, &flight.LaunchTime, &flight.Status, &flight.Capacity, &flight.Booked)
+	if err != nil {
+		return models.Flight{}, err
+	}
+	flight.Remaining = max(flight.Capacity-flight.Booked, 0)
+
+	return flight, nil
+}
+
+// GetFlights returns the flights matching the filter ordered by launch date and time.
+func (db *DB) GetFlights(filter models.FlightFilter) ([]models.Flight, error) {
+	var where conditions
+	if filter.DestinationID != 0 {
+		where.add("f.destination_id = ?", filter.DestinationID)
+	}
+	if filter.LaunchpadID != "" {
+		where.add("f.launchpad_id = ?", filter.LaunchpadID)
+	}
+	if filter.Provider != "" {
+		where.add("f.provider = ?", filter.Provider)
+	}
+	if filter.Status != "" {
+		where.add("f.status = ?", filter.Status)
+	}
+	if !filter.LaunchDateFrom.IsZero() {
+		where.add("f.launch_date >= ?", flightDay(filter.LaunchDateFrom))
+	}
+	if !filter.LaunchDateTo.IsZero() {
+		where.add("f.launch_date <= ?", flightDay(filter.LaunchDateTo))
+	}
+
+	args := append(where.args, filter.Limit)
+	query := `SELECT ` + flightColumns + ` FROM flights f` + where.clause() +
+		fmt.Sprintf(` ORDER BY f.launch_date, f.launch_time, f.id LIMIT $%d;`, len(args))
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetFlights query")
+		}
+	}(rows)
+
+	flights := []models.Flight{}
+	for rows.Next() {
+		flight, err := scanFlight(rows)
+		if err != nil {
+			return nil, err
+		}
+		flights = append(flights, flight)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return flights, nil
+}
+
+// GetFlight returns a single flight. It returns sql.ErrNoRows if the flight does not exist.
+func (db *DB) GetFlight(id int) (models.Flight, error) {
+	query := `SELECT ` + flightColumns + ` FROM flights f WHERE f.id = $1;`
+	return scanFlight(db.QueryRow(query, id))
+}
+
+// MaterializeFlights creates the flights defined by the schedules and their overrides for the given number
+// of days starting at from. Existing flights are kept untouched. It returns the number of created flights.
+func (db *DB) MaterializeFlights(from time.Time, days int) (int, error) {
+	query := `
+        INSERT INTO flights (launchpad_id, destination_id, launch_date, launch_time, capacity, provider)
+        SELECT s.launchpad_id, s.destination_id, d.day::date, s.launch_time, s.capacity, s.provider
+        FROM generate_series($1::date, $1::date + ($2 - 1), INTERVAL '1 day') AS d(day)
+        CROSS JOIN LATERAL effective_schedules(d.day::date) AS s
+        ON CONFLICT (launchpad_id, launch_date, launch_time) DO NOTHING`
+
+	result, err := db.Exec(query, flightDay(from), days)
+	if err != nil {
+		return 0, fmt.Errorf("failed to materialize flights: %w", err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(created), nil
+}
+
+// syncFlights brings the flights already created for the launchpad from the day from on, up to the day to
+// when it is set, in line with the effective schedule of their day: flights at a departure of the day fly to
+// its destination and are scheduled again if they were cancelled, the other flights are cancelled. Completed
+// flights are kept. It returns ErrFlightBooked if a flight with active bookings would be rerouted or cancelled.
+func syncFlights(tx *sql.Tx, launchpadID string, from time.Time, to *time.Time) error {
+	var where conditions
+	where.add("f.launchpad_id = ?", launchpadID)
+	where.add("f.launch_date >= ?", flightDay(from))
+	if to != nil {
+		where.add("f.launch_date <= ?", flightDay(*to))
+	}
+	where.add("f.status <> 'completed'")
+
+	var bookings int
+	countQuery := `
+        SELECT COUNT(b.id) FROM flights f
+        JOIN bookings b ON b.flight_id = f.id AND b.status <> 'cancelled'` + where.clause() + `
+          AND NOT EXISTS (
+              SELECT 1 FROM effective_schedules(f.launch_date) s
+              WHERE s.launchpad_id = f.launchpad_id AND s.launch_time = f.launch_time AND s.destination_id = f.destination_id
+          );`
+	if err := tx.QueryRow(countQuery, where.args...).Scan(&bookings); err != nil {
+		return fmt.Errorf("failed to count flight bookings: %w", err)
+	}
+	if bookings > 0 {
+		return ErrFlightBooked
+	}
+
+	// The effective schedule is joined in a subquery, an UPDATE cannot join a function of the updated row.
+	query := `
+        UPDATE flights
+        SET destination_id = d.destination_id, status = 'scheduled', updated_at = CURRENT_TIMESTAMP
+        FROM (
+            SELECT f.id, s.destination_id FROM flights f
+            CROSS JOIN LATERAL effective_schedules(f.launch_date) s` + where.clause() + `
+              AND s.launchpad_id = f.launchpad_id AND s.launch_time = f.launch_time
+              AND (f.status = 'cancelled' OR f.destination_id <> s.destination_id)
+        ) d
+        WHERE flights.id = d.id;`
+	if _, err := tx.Exec(query, where.args...); err != nil {
+		return fmt.Errorf("failed to update flight: %w", err)
+	}
+
+	cancelQuery := `
+        UPDATE flights f SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP` + where.clause() + `
+          AND f.status = 'scheduled'
+          AND NOT EXISTS (
+              SELECT 1 FROM effective_schedules(f.launch_date) s
+              WHERE s.launchpad_id = f.launchpad_id AND s.launch_time = f.launch_time
+          );`
+	if _, err := tx.Exec(cancelQuery, where.args...); err != nil {
+		return fmt.Errorf("failed to cancel flight: %w", err)
+	}
+
+	return nil
+}
+
+// ensureFlight returns the ID of the flight of the departure, creating it from the effective
+// schedule of the day when it was not materialized yet. It returns ErrFlightRerouted if the flight
+// at the launch time flies to another destination than the departure.
+func ensureFlight(tx *sql.Tx, departure models.Departure) (uint, error) {
+	insertQuery := `
+        INSERT INTO flights (launchpad_id, destination_id, launch_date, launch_time, capacity, provider)
+        SELECT launchpad_id, destination_id, $2, launch_time, capacity, provider FROM effective_schedules($2)
+        WHERE launchpad_id = $1 AND launch_time = $3
+        ON CONFLICT (launchpad_id, launch_date, launch_time) DO NOTHING`
+	day := flightDay(departure.LaunchDate)
+	if _, err := tx.Exec(insertQuery, departure.LaunchpadID, day, departure.LaunchTime); err != nil {
+		return 0, fmt.Errorf("failed to create flight: %w", err)
+	}
+
+	var id uint
+	query := `SELECT id FROM flights WHERE launchpad_id = $1 AND launch_date = $2 AND launch_time = $3 AND destination_id = $4;`
+	err := tx.QueryRow(query, departure.LaunchpadID, day, departure.LaunchTime, departure.DestinationID).Scan(&id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+
+		var exists bool
+		existsQuery := `SELECT EXISTS (SELECT 1 FROM flights WHERE launchpad_id = $1 AND launch_date = $2 AND launch_time = $3);`
+		if err := tx.QueryRow(existsQuery, departure.LaunchpadID, day, departure.LaunchTime).Scan(&exists); err != nil {
+			return 0, err
+		}
+		if exists {
+			return 0, ErrFlightRerouted
+		}
+
+		return 0, fmt.Errorf("missing flight for the provided launchpad at this date and time")
+	}
+
+	return id, nil
+}
+
+// reserveSeat locks the flight of the departure until the transaction ends and makes sure it
+// still has a free seat. excludeID skips the booking being updated. It returns the ID of the flight.
+func reserveSeat(tx *sql.Tx, departure models.Departure, excludeID int) (uint, error) {
+	id, err := ensureFlight(tx, departure)
+	if err != nil {
+		return 0, err
+	}
+
+	// Locking the flight row serializes concurrent reservations on the same flight, so the seat count
+	// below cannot change until the booking is written and the transaction ends.
+	var status models.FlightStatus
+	var capacity int
+	lockQuery := `SELECT status, capacity FROM flights WHERE id = $1 FOR UPDATE;`
+	if err := tx.QueryRow(lockQuery, id).Scan(&status, &capacity); err != nil {
+		return 0, fmt.Errorf("failed to lock flight: %w", err)
+	}
+	if status != models.FlightScheduled {
+		return 0, ErrFlightCancelled
+	}
+
+	var booked int
+	countQuery := `SELECT COUNT(*) FROM bookings WHERE flight_id = $1 AND status <> 'cancelled' AND id <> $2;`
+	if err := tx.QueryRow(countQuery, id, excludeID).Scan(&booked); err != nil {
+		return 0, fmt.Errorf("failed to count booked seats: %w", err)
+	}
+	if booked >= capacity {
+		return 0, ErrFlightFull
+	}
+
+	return id, nil
+}
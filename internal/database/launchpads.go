@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// Resources tracked in the sync_status table.
+const (
+	LaunchpadsResource = "launchpads"
+	LaunchesResource   = "launches"
+)
+
+// SyncLaunchpads replaces the stored launchpads with the launchpads fetched from SpaceX at syncedAt
+// and records the synchronization.
+func (db *DB) SyncLaunchpads(launchpads []models.Launchpad, syncedAt time.Time) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		query := `
+            INSERT INTO launchpads (id, name, full_name, locality, region, latitude, longitude, timezone, status, details, synced_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+            ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, full_name = EXCLUDED.full_name, locality = EXCLUDED.locality,
+                region = EXCLUDED.region, latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, timezone = EXCLUDED.timezone,
+                status = EXCLUDED.status, details = EXCLUDED.details, synced_at = EXCLUDED.synced_at`
+		for _, launchpad := range launchpads {
+			_, err := tx.Exec(query, launchpad.ID, launchpad.Name, launchpad.FullName, launchpad.Locality, launchpad.Region,
+				launchpad.Latitude, launchpad.Longitude, launchpad.Timezone, launchpad.Status, launchpad.Details, syncedAt)
+			if err != nil {
+				return fmt.Errorf("failed to store launchpad %s: %w", launchpad.ID, err)
+			}
+		}
+
+		return finishSync(tx, LaunchpadsResource, len(launchpads), syncedAt)
+	})
+}
+
+// SyncLaunches replaces the stored launches with the upcoming launches fetched from SpaceX at syncedAt
+// and records the synchronization. Launches that are no longer upcoming are removed.
+func (db *DB) SyncLaunches(launches []models.Launch, syncedAt time.Time) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		query := `
+            INSERT INTO launches (id, name, launchpad_id, date_utc, upcoming, synced_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, launchpad_id = EXCLUDED.launchpad_id,
+                date_utc = EXCLUDED.date_utc, upcoming = EXCLUDED.upcoming, synced_at = EXCLUDED.synced_at`
+		for _, launch := range launches {
+			_, err := tx.Exec(query, launch.ID, launch.Name, launch.LaunchpadID, launch.DateUTC, launch.Upcoming, syncedAt)
+			if err != nil {
+				return fmt.Errorf("failed to store launch %s: %w", launch.ID, err)
+			}
+		}
+
+		return finishSync(tx, LaunchesResource, len(launches), syncedAt)
+	})
+}
+
+// finishSync removes the rows of the resource table not seen by the synchronization at syncedAt
+// and records it in sync_status.
+func finishSync(tx *sql.Tx, resource string, count int, syncedAt time.Time) error {
+	// The table name is one of the resource constants, never user input.
+	if _, err := tx.Exec(`DELETE FROM `+resource+` WHERE synced_at < $1;`, syncedAt); err != nil {
+		return fmt.Errorf("failed to delete stale %s: %w", resource, err)
+	}
+
+	query := `
+        INSERT INTO sync_status (resource, synced_at, count) VALUES ($1, $2, $3)
+        ON CONFLICT (resource) DO UPDATE SET synced_at = EXCLUDED.synced_at, count = EXCLUDED.count`
+	if _, err := tx.Exec(query, resource, syncedAt, count); err != nil {
+		return fmt.Errorf("failed to record %s synchronization: %w", resource, err)
+	}
+
+	return nil
+}
+
+// GetLastSync returns the time of the last synchronization of the resource, the zero time when it never ran.
+func (db *DB) GetLastSync(resource string) (time.Time, error) {
+	var syncedAt time.Time
+	err := db.QueryRow(`SELECT synced_at FROM sync_status WHERE resource = $1;`, resource).Scan(&syncedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, err
+	}
+
+	return syncedAt, nil
+}
+
+// GetLaunchpads returns the synchronized launchpads matching the filter ordered by name.
+func (db *DB) GetLaunchpads(filter models.LaunchpadFilter) ([]models.LaunchpadSummary, error) {
+	var where conditions
+	if filter.Status != "" {
+		where.add("status = ?", filter.Status)
+	}
+
+	query := `SELECT id, name, locality, timezone, status, synced_at FROM launchpads` + where.clause() + ` ORDER BY name, id;`
+	rows, err := db.Query(query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetLaunchpads query")
+		}
+	}(rows)
+
+	launchpads := []models.LaunchpadSummary{}
+	for rows.Next() {
+		var launchpad models.LaunchpadSummary
+		err := rows.Scan(&launchpad.ID, &launchpad.Name, &launchpad.Locality, &launchpad.Timezone, &launchpad.Status, &launchpad.SyncedAt)
+		if err != nil {
+			return nil, err
+		}
+		launchpads = append(launchpads, launchpad)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return launchpads, nil
+}
+
+// HasLaunch reports whether a synchronized launch leaves from the launchpad on the UTC day of the launch date.
+func (db *DB) HasLaunch(launchpadID string, launchDate time.Time) (bool, error) {
+	start := launchDate.UTC().Truncate(24 * time.Hour)
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM launches WHERE launchpad_id = $1 AND date_utc >= $2 AND date_utc < $3);`
+	if err := db.QueryRow(query, launchpadID, start, start.Add(24*time.Hour)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look up launches: %w", err)
+	}
+
+	return exists, nil
+}
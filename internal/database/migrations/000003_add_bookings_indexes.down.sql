@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bookings_launch_date_id;
+DROP INDEX IF EXISTS idx_bookings_destination_id;
+DROP INDEX IF EXISTS idx_bookings_launchpad_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_bookings_launch_date_id ON bookings (launch_date, id);
+CREATE INDEX IF NOT EXISTS idx_bookings_destination_id ON bookings (destination_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_launchpad_id ON bookings (launchpad_id);
\ No newline at end of file
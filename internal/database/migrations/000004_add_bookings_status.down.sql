@@ -0,0 +1,2 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_reason;
+ALTER TABLE bookings DROP COLUMN IF EXISTS status;
\ No newline at end of file
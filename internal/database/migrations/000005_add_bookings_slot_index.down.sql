@@ -0,0 +1 @@
+DROP INDEX IF EXISTS unique_active_launchpad_day;
\ No newline at end of file
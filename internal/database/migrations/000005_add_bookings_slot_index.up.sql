@@ -0,0 +1,4 @@
+-- A launchpad can only be booked once per UTC day, cancelled bookings release the slot.
+CREATE UNIQUE INDEX IF NOT EXISTS unique_active_launchpad_day
+    ON bookings (launchpad_id, ((launch_date AT TIME ZONE 'UTC')::date))
+    WHERE status <> 'cancelled';
\ No newline at end of file
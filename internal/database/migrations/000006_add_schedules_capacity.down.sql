@@ -0,0 +1 @@
+ALTER TABLE schedules DROP COLUMN IF EXISTS capacity;
\ No newline at end of file
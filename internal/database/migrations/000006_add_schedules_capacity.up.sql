@@ -0,0 +1,3 @@
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS capacity INT NOT NULL DEFAULT 10 CHECK (capacity > 0);
+-- Flights hold several bookings now, seats are counted against the schedule capacity instead.
+DROP INDEX IF EXISTS unique_active_launchpad_day;
\ No newline at end of file
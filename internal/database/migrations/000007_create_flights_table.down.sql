@@ -0,0 +1,2 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS flight_id;
+DROP TABLE IF EXISTS flights;
\ No newline at end of file
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS flights (
+    id SERIAL PRIMARY KEY,
+    launchpad_id VARCHAR(255) NOT NULL,
+    destination_id INT NOT NULL,
+    launch_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'cancelled', 'completed')),
+    capacity INT NOT NULL CHECK (capacity > 0),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_launchpad_date UNIQUE (launchpad_id, launch_date)
+);
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS flight_id INT REFERENCES flights (id);
+
+-- Create the flights of existing bookings and link the bookings to them.
+INSERT INTO flights (launchpad_id, destination_id, launch_date, capacity)
+SELECT DISTINCT ON (b.launchpad_id, (b.launch_date AT TIME ZONE 'UTC')::date)
+       b.launchpad_id, b.destination_id, (b.launch_date AT TIME ZONE 'UTC')::date, COALESCE(s.capacity, 10)
+FROM bookings b
+LEFT JOIN schedules s
+       ON s.launchpad_id = b.launchpad_id
+      AND s.day_of_week = EXTRACT(DOW FROM (b.launch_date AT TIME ZONE 'UTC'))
+ON CONFLICT (launchpad_id, launch_date) DO NOTHING;
+
+UPDATE bookings b
+SET flight_id = f.id
+FROM flights f
+WHERE b.flight_id IS NULL
+  AND f.launchpad_id = b.launchpad_id
+  AND f.launch_date = (b.launch_date AT TIME ZONE 'UTC')::date;
+
+ALTER TABLE bookings ALTER COLUMN flight_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bookings_flight_id ON bookings (flight_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS effective_schedules(DATE);
+DROP TABLE IF EXISTS schedule_overrides;
\ No newline at end of file
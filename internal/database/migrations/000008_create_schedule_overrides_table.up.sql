@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS schedule_overrides (
+    id SERIAL PRIMARY KEY,
+    launchpad_id VARCHAR(255) NOT NULL,
+    date DATE NOT NULL,
+    -- NULL closes the launchpad for the day.
+    destination_id INT,
+    capacity INT NOT NULL DEFAULT 10 CHECK (capacity > 0),
+    reason VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_launchpad_date_override UNIQUE (launchpad_id, date)
+);
+
+-- effective_schedules returns the launchpads flying on the day with their destination and capacity.
+-- Overrides of the day take precedence over the weekly schedules.
+CREATE OR REPLACE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
\ No newline at end of file
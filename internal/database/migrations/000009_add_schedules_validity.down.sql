@@ -0,0 +1,17 @@
+CREATE OR REPLACE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS unique_launchpad_day;
+ALTER TABLE schedules ADD CONSTRAINT unique_launchpad_day UNIQUE (launchpad_id, day_of_week);
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS valid_range;
+ALTER TABLE schedules DROP COLUMN IF EXISTS valid_to;
+ALTER TABLE schedules DROP COLUMN IF EXISTS valid_from;
\ No newline at end of file
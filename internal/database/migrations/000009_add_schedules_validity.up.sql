@@ -0,0 +1,30 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Existing schedules are valid since the beginning, new versions start on the day they take effect.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS valid_from DATE NOT NULL DEFAULT '1970-01-01';
+ALTER TABLE schedules ALTER COLUMN valid_from SET DEFAULT CURRENT_DATE;
+-- valid_to is exclusive, NULL keeps the version valid until further notice.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS valid_to DATE;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS valid_range;
+ALTER TABLE schedules ADD CONSTRAINT valid_range CHECK (valid_to IS NULL OR valid_to > valid_from);
+
+-- A launchpad can only have one schedule per day of the week at any point in time.
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS unique_launchpad_day;
+ALTER TABLE schedules ADD CONSTRAINT unique_launchpad_day
+    EXCLUDE USING gist (launchpad_id WITH =, day_of_week WITH =, daterange(valid_from, valid_to) WITH &&);
+
+-- effective_schedules returns the launchpads flying on the day with their destination and capacity.
+-- Overrides of the day take precedence over the weekly schedule version valid on the day.
+CREATE OR REPLACE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND s.valid_from <= day
+      AND (s.valid_to IS NULL OR day < s.valid_to)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
\ No newline at end of file
@@ -0,0 +1,27 @@
+DROP FUNCTION IF EXISTS effective_schedules(DATE);
+CREATE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND s.valid_from <= day
+      AND (s.valid_to IS NULL OR day < s.valid_to)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE flights DROP CONSTRAINT IF EXISTS unique_flight_slot;
+ALTER TABLE flights DROP CONSTRAINT IF EXISTS unique_launchpad_date;
+ALTER TABLE flights ADD CONSTRAINT unique_launchpad_date UNIQUE (launchpad_id, launch_date);
+ALTER TABLE flights DROP COLUMN IF EXISTS launch_time;
+
+ALTER TABLE schedule_overrides DROP COLUMN IF EXISTS launch_time;
+
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS unique_schedule_slot;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS unique_launchpad_day;
+ALTER TABLE schedules ADD CONSTRAINT unique_launchpad_day
+    EXCLUDE USING gist (launchpad_id WITH =, day_of_week WITH =, daterange(valid_from, valid_to) WITH &&);
+ALTER TABLE schedules DROP COLUMN IF EXISTS launch_time;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- Launchpads can depart several times a day, each departure slot has its own UTC launch time.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS launch_time TIME NOT NULL DEFAULT '12:00';
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS unique_launchpad_day;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS unique_schedule_slot;
+ALTER TABLE schedules ADD CONSTRAINT unique_schedule_slot
+    EXCLUDE USING gist (launchpad_id WITH =, day_of_week WITH =, launch_time WITH =, daterange(valid_from, valid_to) WITH &&);
+
+ALTER TABLE schedule_overrides ADD COLUMN IF NOT EXISTS launch_time TIME NOT NULL DEFAULT '12:00';
+
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS launch_time TIME NOT NULL DEFAULT '12:00';
+ALTER TABLE flights DROP CONSTRAINT IF EXISTS unique_launchpad_date;
+ALTER TABLE flights DROP CONSTRAINT IF EXISTS unique_flight_slot;
+ALTER TABLE flights ADD CONSTRAINT unique_flight_slot UNIQUE (launchpad_id, launch_date, launch_time);
+
+-- effective_schedules returns the departures of the day with their launchpad, destination, capacity and launch time.
+-- An override of the day replaces every weekly departure of its launchpad with a single one.
+DROP FUNCTION IF EXISTS effective_schedules(DATE);
+CREATE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT, launch_time TIME) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity, o.launch_time
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity, s.launch_time
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND s.valid_from <= day
+      AND (s.valid_to IS NULL OR day < s.valid_to)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS launch_cache;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- launch_cache holds the cached SpaceX API responses when the API server runs with LAUNCH_CACHE=postgres,
+-- so the cache is shared by every instance and survives restarts.
+CREATE TABLE IF NOT EXISTS launch_cache (
+    key TEXT PRIMARY KEY,
+    value BYTEA NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_launch_cache_expires_at ON launch_cache (expires_at);
\ No newline at end of file
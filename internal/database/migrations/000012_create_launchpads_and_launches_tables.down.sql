@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sync_status;
+DROP TABLE IF EXISTS launches;
+DROP TABLE IF EXISTS launchpads;
\ No newline at end of file
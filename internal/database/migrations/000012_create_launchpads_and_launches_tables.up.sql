@@ -0,0 +1,32 @@
+-- launchpads and launches are synchronized from the SpaceX API by the sync binary.
+CREATE TABLE IF NOT EXISTS launchpads (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    full_name VARCHAR(255) NOT NULL,
+    locality VARCHAR(255) NOT NULL,
+    region VARCHAR(255) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    timezone VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    details TEXT NOT NULL,
+    synced_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS launches (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    launchpad_id VARCHAR(255) NOT NULL,
+    date_utc TIMESTAMPTZ NOT NULL,
+    upcoming BOOLEAN NOT NULL,
+    synced_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_launches_launchpad_date ON launches (launchpad_id, date_utc);
+
+-- sync_status holds the time of the last successful synchronization of each resource.
+CREATE TABLE IF NOT EXISTS sync_status (
+    resource VARCHAR(50) PRIMARY KEY,
+    synced_at TIMESTAMPTZ NOT NULL,
+    count INT NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,20 @@
+DROP FUNCTION IF EXISTS effective_schedules(DATE);
+CREATE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT, launch_time TIME) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity, o.launch_time
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity, s.launch_time
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND s.valid_from <= day
+      AND (s.valid_to IS NULL OR day < s.valid_to)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
+
+DROP INDEX IF EXISTS idx_bookings_provider;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS provider;
+ALTER TABLE flights DROP COLUMN IF EXISTS provider;
+ALTER TABLE schedules DROP COLUMN IF EXISTS provider;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- Launchpads belong to launch operators, each checked through its launch provider. Schedules, flights and bookings
+-- record the provider of their launchpad; rows from before the providers were introduced belong to SpaceX.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS provider VARCHAR(50) NOT NULL DEFAULT 'spacex';
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS provider VARCHAR(50) NOT NULL DEFAULT 'spacex';
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS provider VARCHAR(50) NOT NULL DEFAULT 'spacex';
+
+CREATE INDEX IF NOT EXISTS idx_bookings_provider ON bookings (provider);
+
+-- effective_schedules returns the departures of the day with their launchpad, destination, capacity, launch time
+-- and provider. An override of the day replaces every weekly departure of its launchpad with a single one, flown for
+-- the provider of the latest schedule of the launchpad.
+DROP FUNCTION IF EXISTS effective_schedules(DATE);
+CREATE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT, launch_time TIME, provider VARCHAR) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity, o.launch_time,
+        COALESCE((SELECT s.provider FROM schedules s WHERE s.launchpad_id = o.launchpad_id ORDER BY s.valid_from DESC LIMIT 1), 'spacex')
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity, s.launch_time, s.provider
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND s.valid_from <= day
+      AND (s.valid_to IS NULL OR day < s.valid_to)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
\ No newline at end of file
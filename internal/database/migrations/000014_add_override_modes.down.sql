@@ -0,0 +1,33 @@
+DROP FUNCTION IF EXISTS effective_schedules(DATE);
+CREATE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT, launch_time TIME, provider VARCHAR) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity, o.launch_time,
+        COALESCE((SELECT s.provider FROM schedules s WHERE s.launchpad_id = o.launchpad_id ORDER BY s.valid_from DESC LIMIT 1), 'spacex')
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity, s.launch_time, s.provider
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND s.valid_from <= day
+      AND (s.valid_to IS NULL OR day < s.valid_to)
+      AND NOT EXISTS (SELECT 1 FROM schedule_overrides o WHERE o.launchpad_id = s.launchpad_id AND o.date = day)
+$$ LANGUAGE sql STABLE;
+
+-- Added departures and all but the latest override of a launchpad on a date cannot be represented without the modes.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'schedule_overrides' AND column_name = 'mode') THEN
+        DELETE FROM schedule_overrides WHERE mode = 'add';
+    END IF;
+END $$;
+DELETE FROM schedule_overrides o
+USING schedule_overrides newer
+WHERE newer.launchpad_id = o.launchpad_id AND newer.date = o.date AND newer.id > o.id;
+
+ALTER TABLE schedule_overrides DROP CONSTRAINT IF EXISTS unique_override_slot;
+ALTER TABLE schedule_overrides DROP CONSTRAINT IF EXISTS unique_launchpad_date_override;
+ALTER TABLE schedule_overrides ADD CONSTRAINT unique_launchpad_date_override UNIQUE (launchpad_id, date);
+
+ALTER TABLE schedule_overrides DROP CONSTRAINT IF EXISTS override_add_destination;
+ALTER TABLE schedule_overrides DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,32 @@
+-- Overrides either replace every weekly departure of their launchpad on the date (replace), or add a departure to
+-- them (add), e.g. an extra flight on a busy day. A launchpad can have one override per date and launch time.
+ALTER TABLE schedule_overrides ADD COLUMN IF NOT EXISTS mode VARCHAR(10) NOT NULL DEFAULT 'replace' CHECK (mode IN ('replace', 'add'));
+ALTER TABLE schedule_overrides DROP CONSTRAINT IF EXISTS override_add_destination;
+ALTER TABLE schedule_overrides ADD CONSTRAINT override_add_destination CHECK (mode = 'replace' OR destination_id IS NOT NULL);
+
+ALTER TABLE schedule_overrides DROP CONSTRAINT IF EXISTS unique_launchpad_date_override;
+ALTER TABLE schedule_overrides DROP CONSTRAINT IF EXISTS unique_override_slot;
+ALTER TABLE schedule_overrides ADD CONSTRAINT unique_override_slot UNIQUE (launchpad_id, date, launch_time);
+
+-- effective_schedules returns the departures of the day with their launchpad, destination, capacity, launch time
+-- and provider. A replace override of the day replaces every weekly departure of its launchpad, an add override
+-- only replaces the weekly departure at its launch time, if any. Override departures are flown for the provider
+-- of the latest schedule of the launchpad.
+DROP FUNCTION IF EXISTS effective_schedules(DATE);
+CREATE FUNCTION effective_schedules(day DATE)
+RETURNS TABLE (launchpad_id VARCHAR, destination_id INT, capacity INT, launch_time TIME, provider VARCHAR) AS $$
+    SELECT o.launchpad_id, o.destination_id, o.capacity, o.launch_time,
+        COALESCE((SELECT s.provider FROM schedules s WHERE s.launchpad_id = o.launchpad_id ORDER BY s.valid_from DESC LIMIT 1), 'spacex')
+    FROM schedule_overrides o
+    WHERE o.date = day AND o.destination_id IS NOT NULL
+    UNION ALL
+    SELECT s.launchpad_id, s.destination_id, s.capacity, s.launch_time, s.provider
+    FROM schedules s
+    WHERE s.day_of_week = EXTRACT(DOW FROM day)
+      AND s.valid_from <= day
+      AND (s.valid_to IS NULL OR day < s.valid_to)
+      AND NOT EXISTS (
+          SELECT 1 FROM schedule_overrides o
+          WHERE o.launchpad_id = s.launchpad_id AND o.date = day AND (o.mode = 'replace' OR o.launch_time = s.launch_time)
+      )
+$$ LANGUAGE sql STABLE;
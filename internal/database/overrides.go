@@ -0,0 +1,181 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// ErrOverrideConflict is returned when an override, or its removal, would reroute or cancel a flight that already has bookings.
+var ErrOverrideConflict = errors.New("flight on this date already has bookings that would be rerouted or cancelled")
+
+// overrideColumns lists the schedule_overrides columns in the order expected by scanOverride.
+const overrideColumns = `id, launchpad_id, date, mode, destination_id, to_char(launch_time, 'HH24:MI'), capacity, COALESCE(reason, ''), created_at`
+
+// scanOverride scans a row selected with overrideColumns into a ScheduleOverride.
+func scanOverride(row rowScanner) (models.ScheduleOverride, error) {
+	var override models.ScheduleOverride
+	var destinationID sql.NullInt64
+	err := row.Scan(&override.ID, &override.LaunchpadID, &override.Date, &override.Mode, &destinationID, &override.LaunchTime, &override.Capacity, &override.Reason, &override.CreatedAt)
+	if err != nil {
+		return models.ScheduleOverride{}, err
+	}
+	if destinationID.Valid {
+		destination := models.Destination(destinationID.Int64)
+		override.DestinationID = &destination
+	}
+
+	return override, nil
+}
+
+// GetScheduleOverrides returns the overrides matching the filter ordered by date and launchpad.
+func (db *DB) GetScheduleOverrides(filter models.ScheduleOverrideFilter) ([]models.ScheduleOverride, error) {
+	var where conditions
+	if filter.LaunchpadID != "" {
+		where.add("launchpad_id = ?", filter.LaunchpadID)
+	}
+	if !filter.From.IsZero() {
+		where.add("date >= ?", flightDay(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where.add("date <= ?", flightDay(filter.To))
+	}
+
+	query := `SELECT ` + overrideColumns + ` FROM schedule_overrides` + where.clause() + ` ORDER BY date, launchpad_id, launch_time;`
+	rows, err := db.Query(query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetScheduleOverrides query")
+		}
+	}(rows)
+
+	overrides := []models.ScheduleOverride{}
+	for rows.Next() {
+		override, err := scanOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+// InsertScheduleOverride creates or replaces the override of the launchpad on the date and launch time, and brings
+// the already created flights in line with it: a closure cancels the flights of the day and removes the other
+// overrides of the date, while a departure reopens a closed day. The flight at the override's launch time is updated,
+// and a replace override cancels the other flights of the day. It returns ErrOverrideConflict if a flight
+// that would be rerouted or cancelled, including by a closure, already has active bookings.
+func (db *DB) InsertScheduleOverride(request models.ScheduleOverrideRequest) (models.ScheduleOverride, error) {
+	// A closure closes the whole day, a departure replaces the closure of the day.
+	removeQuery := `DELETE FROM schedule_overrides WHERE launchpad_id = $1 AND date = $2 AND launch_time <> $3 AND destination_id IS NULL;`
+	if request.DestinationID == nil {
+		removeQuery = `DELETE FROM schedule_overrides WHERE launchpad_id = $1 AND date = $2 AND launch_time <> $3;`
+	}
+	query := `
+        INSERT INTO schedule_overrides (launchpad_id, date, mode, destination_id, launch_time, capacity, reason)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+        ON CONFLICT (launchpad_id, date, launch_time) DO UPDATE
+        SET mode = EXCLUDED.mode,
+            destination_id = EXCLUDED.destination_id,
+            launch_time = EXCLUDED.launch_time,
+            capacity = EXCLUDED.capacity,
+            reason = EXCLUDED.reason,
+            updated_at = CURRENT_TIMESTAMP
+        RETURNING ` + overrideColumns
+
+	var override models.ScheduleOverride
+	err := db.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(removeQuery, request.LaunchpadID, flightDay(request.Date), request.LaunchTime); err != nil {
+			return fmt.Errorf("failed to remove schedule overrides: %w", err)
+		}
+
+		var err error
+		override, err = scanOverride(tx.QueryRow(query,
+			request.LaunchpadID,
+			flightDay(request.Date),
+			request.Mode,
+			request.DestinationID,
+			request.LaunchTime,
+			request.Capacity,
+			request.Reason,
+		))
+		if err != nil {
+			return fmt.Errorf("failed to insert schedule override: %w", err)
+		}
+
+		return syncOverriddenFlight(tx, override)
+	})
+	if err != nil {
+		return models.ScheduleOverride{}, err
+	}
+
+	return override, nil
+}
+
+// syncOverriddenFlight updates the flights already created for the launchpad on the override date.
+func syncOverriddenFlight(tx *sql.Tx, override models.ScheduleOverride) error {
+	if err := syncDayFlights(tx, override.LaunchpadID, override.Date); err != nil {
+		return err
+	}
+	if override.DestinationID == nil {
+		return nil
+	}
+
+	query := `
+        UPDATE flights SET capacity = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE launchpad_id = $1 AND launch_date = $2 AND launch_time = $3 AND capacity <> $4;`
+	if _, err := tx.Exec(query, override.LaunchpadID, flightDay(override.Date), override.LaunchTime, override.Capacity); err != nil {
+		return fmt.Errorf("failed to update flight: %w", err)
+	}
+
+	return nil
+}
+
+// syncDayFlights brings the flights already created for the launchpad on the day in line with its effective
+// schedule, see syncFlights. It returns ErrOverrideConflict if a flight with active bookings would be
+// rerouted or cancelled, the bookings have to be moved or cancelled first.
+func syncDayFlights(tx *sql.Tx, launchpadID string, date time.Time) error {
+	err := syncFlights(tx, launchpadID, date, &date)
+	if errors.Is(err, ErrFlightBooked) {
+		return ErrOverrideConflict
+	}
+
+	return err
+}
+
+// DeleteScheduleOverride deletes an override, so the weekly schedule applies on its date again.
+// Flights created for the date without any bookings are removed, so they are recreated from the weekly schedule.
+// Flights with bookings are brought in line with the weekly schedule, and flights the override cancelled are
+// scheduled again. It returns ErrOverrideConflict if a flight with active bookings is not in the weekly schedule.
+func (db *DB) DeleteScheduleOverride(id int) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		var launchpadID string
+		var date time.Time
+		query := `DELETE FROM schedule_overrides WHERE id = $1 RETURNING launchpad_id, date;`
+		if err := tx.QueryRow(query, id).Scan(&launchpadID, &date); err != nil {
+			return err
+		}
+
+		flightQuery := `
+            DELETE FROM flights f
+            WHERE f.launchpad_id = $1 AND f.launch_date = $2
+              AND NOT EXISTS (SELECT 1 FROM bookings b WHERE b.flight_id = f.id);`
+		if _, err := tx.Exec(flightQuery, launchpadID, flightDay(date)); err != nil {
+			return fmt.Errorf("failed to remove flight: %w", err)
+		}
+
+		return syncDayFlights(tx, launchpadID, date)
+	})
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// bookingCursor holds the sort key of the last booking on a page.
+type bookingCursor struct {
+	ID         uint      `json:"id"`
+	LaunchDate time.Time `json:"launch_date"`
+}
+
+// encodeCursor creates an opaque cursor pointing after the given booking.
+func encodeCursor(booking models.Booking) string {
+	data, _ := json.Marshal(bookingCursor{ID: booking.ID, LaunchDate: booking.LaunchDate})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor created by encodeCursor.
+func decodeCursor(cursor string) (bookingCursor, error) {
+	var result bookingCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return result, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, ErrInvalidCursor
+	}
+
+	return result, nil
+}
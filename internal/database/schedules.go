@@ -0,0 +1,279 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// ErrScheduleConflict is returned when the launchpad already has a departure at the launch time on the day of the week.
+var ErrScheduleConflict = errors.New("launchpad already has a departure at this time on this day of the week in this period")
+
+const (
+	// uniqueViolation is the Postgres error code for unique constraint violations.
+	uniqueViolation = "23505"
+	// exclusionViolation is the Postgres error code for exclusion constraint violations.
+	exclusionViolation = "23P01"
+)
+
+// scheduleColumns lists the schedules columns in the order expected by scanSchedule.
+const scheduleColumns = `id, launchpad_id, provider, destination_id, day_of_week, to_char(launch_time, 'HH24:MI'), capacity, valid_from, valid_to,
+    created_at, updated_at`
+
+// scanSchedule scans a row selected with scheduleColumns into a Schedule.
+func scanSchedule(row rowScanner) (models.Schedule, error) {
+	var schedule models.Schedule
+	err := row.Scan(&schedule.ID, &schedule.LaunchpadID, &schedule.Provider, &schedule.Destination, &schedule.DayOfWeek, &schedule.LaunchTime, &schedule.Capacity, &schedule.ValidFrom, &schedule.ValidTo, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// GetSchedules returns the schedules matching the filter ordered by launchpad, day of the week, launch time and validity.
+// Unless the filter asks for the history, only the versions valid on filter.At are returned.
+func (db *DB) GetSchedules(filter models.ScheduleFilter) ([]models.Schedule, error) {
+	var where conditions
+	if !filter.History {
+		where.add("valid_from <= ? AND (valid_to IS NULL OR ? < valid_to)", flightDay(filter.At), flightDay(filter.At))
+	}
+	if filter.LaunchpadID != "" {
+		where.add("launchpad_id = ?", filter.LaunchpadID)
+	}
+	if filter.Provider != "" {
+		where.add("provider = ?", filter.Provider)
+	}
+	if filter.DestinationID != 0 {
+		where.add("destination_id = ?", filter.DestinationID)
+	}
+	if filter.DayOfWeek != nil {
+		where.add("day_of_week = ?", *filter.DayOfWeek)
+	}
+
+	query := `SELECT ` + scheduleColumns + ` FROM schedules` + where.clause() + ` ORDER BY launchpad_id, day_of_week, launch_time, valid_from;`
+	rows, err := db.Query(query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetSchedules query")
+		}
+	}(rows)
+
+	schedules := []models.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// GetSchedule returns a single schedule. It returns sql.ErrNoRows if the schedule does not exist.
+func (db *DB) GetSchedule(id int) (models.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = $1;`
+	return scanSchedule(db.QueryRow(query, id))
+}
+
+// InsertSchedule inserts a schedule version and brings the flights already created for its launchpad in line with it,
+// see syncScheduleFlights. It returns ErrScheduleConflict if the launchpad already has a departure at the launch time
+// on the day of the week in an overlapping period, and ErrFlightBooked if a flight with active bookings would be
+// rerouted or cancelled.
+func (db *DB) InsertSchedule(request models.ScheduleRequest) (models.Schedule, error) {
+	var schedule models.Schedule
+	err := db.withTx(func(tx *sql.Tx) error {
+		var err error
+		schedule, err = insertSchedule(tx.QueryRow, request)
+		if err != nil {
+			return err
+		}
+
+		return syncScheduleFlights(tx, []string{schedule.LaunchpadID}, schedule.ValidFrom)
+	})
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// insertSchedule inserts a schedule version with the queryRow function of a DB or a transaction.
+func insertSchedule(queryRow func(query string, args ...interface{}) *sql.Row, request models.ScheduleRequest) (models.Schedule, error) {
+	query := `
+        INSERT INTO schedules (launchpad_id, destination_id, day_of_week, launch_time, capacity, valid_from, valid_to, provider)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING ` + scheduleColumns
+
+	schedule, err := scanSchedule(queryRow(query, request.LaunchpadID, request.DestinationID, *request.DayOfWeek, request.LaunchTime,
+		request.Capacity, flightDay(request.ValidFrom), nullableDay(request.ValidTo), request.Provider))
+	if err != nil {
+		return models.Schedule{}, mapScheduleError(fmt.Errorf("failed to insert schedule: %w", err))
+	}
+
+	return schedule, nil
+}
+
+// UpdateSchedule updates a schedule version in place and brings the flights already created for its old and new
+// launchpad in line with it, see syncScheduleFlights. It returns sql.ErrNoRows if the schedule does not exist,
+// ErrScheduleConflict if the launchpad already has another departure at the launch time on the day of the week
+// in an overlapping period, and ErrFlightBooked if a flight with active bookings would be rerouted or cancelled.
+func (db *DB) UpdateSchedule(id int, request models.ScheduleRequest) (models.Schedule, error) {
+	query := `
+        UPDATE schedules
+        SET launchpad_id = $1, destination_id = $2, day_of_week = $3, launch_time = $4, capacity = $5, valid_from = $6,
+            valid_to = $7, provider = $8, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $9
+        RETURNING ` + scheduleColumns
+
+	var schedule models.Schedule
+	err := db.withTx(func(tx *sql.Tx) error {
+		var launchpadID string
+		var validFrom time.Time
+		lockQuery := `SELECT launchpad_id, valid_from FROM schedules WHERE id = $1 FOR UPDATE;`
+		if err := tx.QueryRow(lockQuery, id).Scan(&launchpadID, &validFrom); err != nil {
+			return err
+		}
+
+		var err error
+		schedule, err = scanSchedule(tx.QueryRow(query, request.LaunchpadID, request.DestinationID, *request.DayOfWeek, request.LaunchTime,
+			request.Capacity, flightDay(request.ValidFrom), nullableDay(request.ValidTo), request.Provider, id))
+		if err != nil {
+			return mapScheduleError(fmt.Errorf("failed to update schedule: %w", err))
+		}
+
+		if schedule.ValidFrom.Before(validFrom) {
+			validFrom = schedule.ValidFrom
+		}
+
+		return syncScheduleFlights(tx, []string{launchpadID, schedule.LaunchpadID}, validFrom)
+	})
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule deletes a schedule and brings the flights already created for its launchpad in line with the
+// remaining schedules, see syncScheduleFlights. It returns sql.ErrNoRows if the schedule does not exist and
+// ErrFlightBooked if a flight with active bookings would be cancelled.
+func (db *DB) DeleteSchedule(id int) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		var launchpadID string
+		var validFrom time.Time
+		query := `DELETE FROM schedules WHERE id = $1 RETURNING launchpad_id, valid_from;`
+		if err := tx.QueryRow(query, id).Scan(&launchpadID, &validFrom); err != nil {
+			return err
+		}
+
+		return syncScheduleFlights(tx, []string{launchpadID}, validFrom)
+	})
+}
+
+// closeSchedule ends a schedule version on the given day within the transaction, so it no longer applies from
+// that day on. A version that would not be valid on any day is deleted instead. It returns the launchpad of the version.
+func closeSchedule(tx *sql.Tx, id int, validTo time.Time) (string, error) {
+	var launchpadID string
+	query := `SELECT launchpad_id FROM schedules WHERE id = $1 FOR UPDATE;`
+	if err := tx.QueryRow(query, id).Scan(&launchpadID); err != nil {
+		return "", err
+	}
+
+	query = `DELETE FROM schedules WHERE id = $1 AND valid_from >= $2;`
+	result, err := tx.Exec(query, id, flightDay(validTo))
+	if err != nil {
+		return "", fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil || deleted > 0 {
+		return launchpadID, err
+	}
+
+	query = `UPDATE schedules SET valid_to = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND (valid_to IS NULL OR valid_to > $2);`
+	if _, err := tx.Exec(query, id, flightDay(validTo)); err != nil {
+		return "", fmt.Errorf("failed to close schedule: %w", err)
+	}
+
+	return launchpadID, nil
+}
+
+// ReplaceSchedules closes the schedule versions on the given day, see closeSchedule, and inserts the new versions
+// in a single transaction, so a failure leaves the schedules unchanged. The flights already created for the changed
+// launchpads are brought in line with the new versions, see syncScheduleFlights. It returns ErrScheduleConflict
+// if a new version overlaps a departure that is not closed, and ErrFlightBooked if a flight with active bookings
+// would be rerouted or cancelled.
+func (db *DB) ReplaceSchedules(closeIDs []uint, requests []models.ScheduleRequest, validFrom time.Time) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		var launchpadIDs []string
+		for _, id := range closeIDs {
+			launchpadID, err := closeSchedule(tx, int(id), validFrom)
+			if err != nil {
+				return fmt.Errorf("failed to close schedule %d: %w", id, err)
+			}
+			launchpadIDs = append(launchpadIDs, launchpadID)
+		}
+		for _, request := range requests {
+			if _, err := insertSchedule(tx.QueryRow, request); err != nil {
+				return fmt.Errorf("failed to insert schedule for %s on %s: %w", request.LaunchpadID, *request.DayOfWeek, err)
+			}
+			launchpadIDs = append(launchpadIDs, request.LaunchpadID)
+		}
+
+		return syncScheduleFlights(tx, launchpadIDs, validFrom)
+	})
+}
+
+// syncScheduleFlights brings the flights already created for the launchpads from the day from on in line with
+// the schedules, see syncFlights. Flights of past days are kept as they flew.
+func syncScheduleFlights(tx *sql.Tx, launchpadIDs []string, from time.Time) error {
+	if today := time.Now().UTC().Truncate(24 * time.Hour); from.Before(today) {
+		from = today
+	}
+
+	synced := make(map[string]bool, len(launchpadIDs))
+	for _, launchpadID := range launchpadIDs {
+		if synced[launchpadID] {
+			continue
+		}
+		synced[launchpadID] = true
+
+		if err := syncFlights(tx, launchpadID, from, nil); err != nil {
+			return fmt.Errorf("failed to sync flights of %s: %w", launchpadID, err)
+		}
+	}
+
+	return nil
+}
+
+// nullableDay converts an optional date to a value for a nullable DATE column.
+func nullableDay(day *time.Time) interface{} {
+	if day == nil {
+		return nil
+	}
+
+	return flightDay(*day)
+}
+
+// mapScheduleError converts violations of the unique_schedule_slot constraint into ErrScheduleConflict.
+func mapScheduleError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Code == uniqueViolation || pqErr.Code == exclusionViolation) && pqErr.Constraint == "unique_schedule_slot" {
+		return ErrScheduleConflict
+	}
+
+	return err
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// ErrFlightFull is returned when every seat of the flight is already booked.
+var ErrFlightFull = errors.New("flight is fully booked")
+
+// withTx runs fn in a transaction that is committed when fn succeeds and rolled back otherwise.
+func (db *DB) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Printf("failed to rollback transaction: %v", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetDepartures returns the departures to the destination on the launch date ordered by launch time and launchpad,
+// with the capacity and the booked seats of their flights. Departures whose flight was not materialized yet take
+// their capacity from the effective schedule of the day, and departures of cancelled flights are left out.
+func (db *DB) GetDepartures(destinationID models.Destination, launchDate time.Time) ([]models.Departure, error) {
+	query := `
+        SELECT
+            s.launchpad_id,
+            COALESCE(f.provider, s.provider),
+            to_char(s.launch_time, 'HH24:MI'),
+            COALESCE(f.capacity, s.capacity),
+            (SELECT COUNT(*) FROM bookings b WHERE b.flight_id = f.id AND b.status <> 'cancelled')
+        FROM effective_schedules($2) s
+        LEFT JOIN flights f ON f.launchpad_id = s.launchpad_id AND f.launch_date = $2 AND f.launch_time = s.launch_time
+        WHERE COALESCE(f.destination_id, s.destination_id) = $1
+          AND (f.id IS NULL OR f.status = 'scheduled')
+        ORDER BY s.launch_time, s.launchpad_id;`
+	rows, err := db.Query(query, destinationID, flightDay(launchDate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch departures: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Fatal("failed to close rows in GetDepartures query")
+		}
+	}(rows)
+
+	var departures []models.Departure
+	for rows.Next() {
+		departure := models.Departure{
+			DestinationID: destinationID,
+			LaunchDate:    launchDate,
+		}
+		if err := rows.Scan(&departure.LaunchpadID, &departure.Provider, &departure.LaunchTime, &departure.Capacity, &departure.Booked); err != nil {
+			return nil, err
+		}
+		departure.Remaining = max(departure.Capacity-departure.Booked, 0)
+		departures = append(departures, departure)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return departures, nil
+}
+
+// flightDay returns the UTC day of the launch, which identifies the flight together with the launchpad and the launch time.
+func flightDay(launchDate time.Time) string {
+	return launchDate.UTC().Format(time.DateOnly)
+}
@@ -0,0 +1,90 @@
+package external
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState is the state of a circuitBreaker.
+type breakerState int
+
+const (
+	// breakerClosed lets every request through.
+	breakerClosed breakerState = iota
+	// breakerOpen fails every request fast until the cooldown has passed.
+	breakerOpen
+	// breakerHalfOpen lets a single trial request through after the cooldown.
+	breakerHalfOpen
+)
+
+// circuitBreaker stops calling the SpaceX API after a number of consecutive failed calls, so bookings fail fast
+// instead of waiting on timeouts and retries while the API is down. After the cooldown a single trial call
+// is let through, which closes the breaker on success and opens it again on failure.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+}
+
+// newCircuitBreaker returns a closed breaker opening after threshold consecutive failures.
+// A threshold of 0 disables the breaker.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a call may be made. Once the cooldown of an open breaker has passed,
+// it lets the caller through as the trial call and keeps rejecting the others until it reports back.
+func (b *circuitBreaker) allow() bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// success records a call that reached the API and closes the breaker.
+func (b *circuitBreaker) success() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// failure records a call that failed after its retries and opens the breaker when the threshold is reached
+// or the trial call failed.
+func (b *circuitBreaker) failure() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
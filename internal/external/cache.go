@@ -0,0 +1,273 @@
+package external
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// CacheBackend stores the cached responses of a CachedLaunchProvider.
+type CacheBackend interface {
+	// Get returns the value of the key, or false when it is missing or expired.
+	Get(key string) ([]byte, bool, error)
+	// Set stores the value of the key for the ttl.
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes the entries whose key starts with the prefix, every entry for an empty prefix,
+	// and returns the number of removed entries.
+	Delete(prefix string) (int, error)
+	// DeleteKey removes the entry of the key and returns the number of removed entries.
+	DeleteKey(key string) (int, error)
+}
+
+// MemoryCache is an in-process CacheBackend. It is safe for concurrent use.
+type MemoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// memorySweepInterval is how often Set removes the expired entries of a MemoryCache.
+const memorySweepInterval = time.Minute
+
+// NewMemoryCache creates an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries:   make(map[string]memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= memorySweepInterval {
+		for k, entry := range m.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	m.entries[key] = memoryEntry{value: value, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+func (m *MemoryCache) Delete(prefix string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (m *MemoryCache) DeleteKey(key string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.entries[key]; !ok {
+		return 0, nil
+	}
+	delete(m.entries, key)
+
+	return 1, nil
+}
+
+// Cache key prefixes of the cached lookups. Launch queries are keyed by their launchpad, when they filter
+// by one, so invalidating a launchpad also drops its launch queries.
+const (
+	launchesKeyPrefix  = "launches:"
+	launchpadKeyPrefix = "launchpad:"
+)
+
+// cacheCounters counts the lookups of one kind of cached call.
+type cacheCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+func (c *cacheCounters) stats() models.CacheCounters {
+	stats := models.CacheCounters{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+
+	return stats
+}
+
+// CachedLaunchProvider caches the launch queries and launchpad states of another LaunchProvider for a TTL,
+// since many bookings check the same launchpad and day. Failed calls are not cached, and a failing backend
+// only costs the cache: the call then goes to the provider. GetActiveLaunchpads is not cached, so the
+// schedule generator always sees the current launchpads.
+type CachedLaunchProvider struct {
+	provider   LaunchProvider
+	backend    CacheBackend
+	ttl        time.Duration
+	launches   cacheCounters
+	launchpads cacheCounters
+}
+
+// NewCachedLaunchProvider creates a CachedLaunchProvider keeping the responses of the provider in the backend for the ttl.
+func NewCachedLaunchProvider(provider LaunchProvider, backend CacheBackend, ttl time.Duration) *CachedLaunchProvider {
+	return &CachedLaunchProvider{
+		provider: provider,
+		backend:  backend,
+		ttl:      ttl,
+	}
+}
+
+// CheckScheduledLaunches returns the cached launches of the query or asks the provider.
+func (c *CachedLaunchProvider) CheckScheduledLaunches(body models.RequestBody) (models.FilteredResponse, error) {
+	key, err := launchesKey(body)
+	if err != nil {
+		return models.FilteredResponse{}, err
+	}
+
+	var result models.FilteredResponse
+	if c.lookup(key, &result, &c.launches) {
+		return result, nil
+	}
+
+	result, err = c.provider.CheckScheduledLaunches(body)
+	if err != nil {
+		return result, err
+	}
+	c.store(key, result, &c.launches)
+
+	return result, nil
+}
+
+// CheckLaunchpadState returns the cached state of the launchpad or asks the provider.
+func (c *CachedLaunchProvider) CheckLaunchpadState(id string) (string, error) {
+	key := launchpadKeyPrefix + id
+
+	var state string
+	if c.lookup(key, &state, &c.launchpads) {
+		return state, nil
+	}
+
+	state, err := c.provider.CheckLaunchpadState(id)
+	if err != nil {
+		return "", err
+	}
+	c.store(key, state, &c.launchpads)
+
+	return state, nil
+}
+
+// GetActiveLaunchpads asks the provider.
+func (c *CachedLaunchProvider) GetActiveLaunchpads(body models.RequestBody) ([]models.Filtered, error) {
+	return c.provider.GetActiveLaunchpads(body)
+}
+
+// Stats returns the hits, misses and backend errors of the cached calls since the start.
+func (c *CachedLaunchProvider) Stats() models.LaunchCacheStats {
+	return models.LaunchCacheStats{
+		TTLSeconds: int(c.ttl.Seconds()),
+		Launches:   c.launches.stats(),
+		Launchpads: c.launchpads.stats(),
+	}
+}
+
+// Invalidate removes the cached launches and state of the launchpad, or every entry when launchpadID is empty,
+// and returns the number of removed entries.
+func (c *CachedLaunchProvider) Invalidate(launchpadID string) (int, error) {
+	if launchpadID == "" {
+		return c.backend.Delete("")
+	}
+
+	launches, err := c.backend.Delete(launchesKeyPrefix + launchpadID + ":")
+	if err != nil {
+		return 0, err
+	}
+	// The state is deleted by its exact key, a prefix would also match the launchpads whose ID extends this one.
+	launchpads, err := c.backend.DeleteKey(launchpadKeyPrefix + launchpadID)
+	if err != nil {
+		return 0, err
+	}
+
+	return launches + launchpads, nil
+}
+
+// lookup decodes the cached value of the key into value and reports whether it was found.
+func (c *CachedLaunchProvider) lookup(key string, value interface{}, counters *cacheCounters) bool {
+	data, ok, err := c.backend.Get(key)
+	if err == nil && ok {
+		err = json.Unmarshal(data, value)
+		if err == nil {
+			counters.hits.Add(1)
+			return true
+		}
+	}
+	if err != nil {
+		counters.errors.Add(1)
+		log.Printf("failed to read launch cache entry %s: %v", key, err)
+	}
+	counters.misses.Add(1)
+
+	return false
+}
+
+// store caches the value of the key for the TTL.
+func (c *CachedLaunchProvider) store(key string, value interface{}, counters *cacheCounters) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = c.backend.Set(key, data, c.ttl)
+	}
+	if err != nil {
+		counters.errors.Add(1)
+		log.Printf("failed to write launch cache entry %s: %v", key, err)
+	}
+}
+
+// launchesKey returns the cache key of a launch query: its launchpad, if it filters by one,
+// followed by the hash of the whole request body.
+func launchesKey(body models.RequestBody) (string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	hash := sha256.Sum256(data)
+
+	launchpadID, _ := body.Query["launchpad"].(string)
+	return launchesKeyPrefix + launchpadID + ":" + hex.EncodeToString(hash[:16]), nil
+}
@@ -0,0 +1,169 @@
+package external
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// countingProvider counts the calls that reach the wrapped provider.
+type countingProvider struct {
+	*FakeLaunchProvider
+	launchCalls    int
+	launchpadCalls int
+}
+
+func (p *countingProvider) CheckScheduledLaunches(body models.RequestBody) (models.FilteredResponse, error) {
+	p.launchCalls++
+	return p.FakeLaunchProvider.CheckScheduledLaunches(body)
+}
+
+func (p *countingProvider) CheckLaunchpadState(id string) (string, error) {
+	p.launchpadCalls++
+	return p.FakeLaunchProvider.CheckLaunchpadState(id)
+}
+
+// newCountingProvider returns a provider with the launchpads acme-1 and acme-10, each with a launch on 2026-11-02.
+func newCountingProvider() *countingProvider {
+	fake := NewFakeLaunchProvider()
+	for _, id := range []string{"acme-1", "acme-10"} {
+		fake.AddLaunchpad(models.Launchpad{ID: id, Status: "active"})
+		fake.AddLaunch(FakeLaunch{ID: "launch-" + id, LaunchpadID: id, DateUTC: time.Date(2026, 11, 2, 15, 0, 0, 0, time.UTC)})
+	}
+
+	return &countingProvider{FakeLaunchProvider: fake}
+}
+
+// launchesOn returns the query of the launches from the launchpad on 2026-11-02.
+func launchesOn(launchpadID string) models.RequestBody {
+	return NewQuery(Eq("launchpad", launchpadID), Range("date_utc", gte, lt)).Select("id").Body()
+}
+
+func TestCachedLaunchProviderCountsHitsAndMisses(t *testing.T) {
+	provider := newCountingProvider()
+	cached := NewCachedLaunchProvider(provider, NewMemoryCache(), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		result, err := cached.CheckScheduledLaunches(launchesOn("acme-1"))
+		if err != nil {
+			t.Fatalf("CheckScheduledLaunches: %v", err)
+		}
+		if len(result.Docs) != 1 || result.Docs[0].ID != "launch-acme-1" {
+			t.Fatalf("got launches %v, want launch-acme-1", result.Docs)
+		}
+		state, err := cached.CheckLaunchpadState("acme-1")
+		if err != nil {
+			t.Fatalf("CheckLaunchpadState: %v", err)
+		}
+		if state != "active" {
+			t.Fatalf("got state %q, want active", state)
+		}
+	}
+
+	if provider.launchCalls != 1 || provider.launchpadCalls != 1 {
+		t.Errorf("provider called %d times for launches and %d times for launchpads, want once each",
+			provider.launchCalls, provider.launchpadCalls)
+	}
+	stats := cached.Stats()
+	want := models.CacheCounters{Hits: 2, Misses: 1, HitRatio: 2.0 / 3.0}
+	if stats.Launches != want {
+		t.Errorf("launches stats = %+v, want %+v", stats.Launches, want)
+	}
+	if stats.Launchpads != want {
+		t.Errorf("launchpads stats = %+v, want %+v", stats.Launchpads, want)
+	}
+	if stats.TTLSeconds != 60 {
+		t.Errorf("TTLSeconds = %d, want 60", stats.TTLSeconds)
+	}
+}
+
+func TestCachedLaunchProviderDoesNotCacheFailures(t *testing.T) {
+	provider := newCountingProvider()
+	cached := NewCachedLaunchProvider(provider, NewMemoryCache(), time.Minute)
+
+	provider.SetError(ErrUnavailable)
+	if _, err := cached.CheckLaunchpadState("acme-1"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got error %v, want ErrUnavailable", err)
+	}
+
+	provider.SetError(nil)
+	if _, err := cached.CheckLaunchpadState("acme-1"); err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if provider.launchpadCalls != 2 {
+		t.Errorf("provider called %d times, want 2", provider.launchpadCalls)
+	}
+}
+
+func TestCachedLaunchProviderExpiresEntries(t *testing.T) {
+	provider := newCountingProvider()
+	ttl := 20 * time.Millisecond
+	cached := NewCachedLaunchProvider(provider, NewMemoryCache(), ttl)
+
+	if _, err := cached.CheckLaunchpadState("acme-1"); err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if _, err := cached.CheckLaunchpadState("acme-1"); err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if provider.launchpadCalls != 1 {
+		t.Fatalf("provider called %d times before the TTL, want 1", provider.launchpadCalls)
+	}
+
+	time.Sleep(2 * ttl)
+	if _, err := cached.CheckLaunchpadState("acme-1"); err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if provider.launchpadCalls != 2 {
+		t.Errorf("provider called %d times after the TTL, want 2", provider.launchpadCalls)
+	}
+	if stats := cached.Stats().Launchpads; stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("launchpads stats = %+v, want 1 hit and 2 misses", stats)
+	}
+}
+
+func TestCachedLaunchProviderInvalidatesOnlyTheLaunchpad(t *testing.T) {
+	provider := newCountingProvider()
+	cached := NewCachedLaunchProvider(provider, NewMemoryCache(), time.Minute)
+
+	for _, id := range []string{"acme-1", "acme-10"} {
+		if _, err := cached.CheckScheduledLaunches(launchesOn(id)); err != nil {
+			t.Fatalf("CheckScheduledLaunches: %v", err)
+		}
+		if _, err := cached.CheckLaunchpadState(id); err != nil {
+			t.Fatalf("CheckLaunchpadState: %v", err)
+		}
+	}
+
+	removed, err := cached.Invalidate("acme-1")
+	if err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Invalidate removed %d entries, want 2", removed)
+	}
+
+	// acme-10 is still cached, acme-1 goes to the provider again.
+	for _, id := range []string{"acme-1", "acme-10"} {
+		if _, err := cached.CheckScheduledLaunches(launchesOn(id)); err != nil {
+			t.Fatalf("CheckScheduledLaunches: %v", err)
+		}
+		if _, err := cached.CheckLaunchpadState(id); err != nil {
+			t.Fatalf("CheckLaunchpadState: %v", err)
+		}
+	}
+	if provider.launchCalls != 3 || provider.launchpadCalls != 3 {
+		t.Errorf("provider called %d times for launches and %d times for launchpads, want 3 each",
+			provider.launchCalls, provider.launchpadCalls)
+	}
+
+	removed, err = cached.Invalidate("")
+	if err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+	if removed != 4 {
+		t.Errorf("Invalidate removed %d entries, want every 4 entries", removed)
+	}
+}
@@ -3,58 +3,114 @@ package external
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/klemis/go-spaceflight-booking-api/models"
 )
 
+// DefaultBaseURL is the base URL of the public SpaceX API v4.
+const DefaultBaseURL = "https://api.spacexdata.com/v4/"
+
+var (
+	// ErrUnavailable is returned when the SpaceX API cannot be reached or keeps failing after the retries.
+	ErrUnavailable = errors.New("spacex api is unavailable")
+	// ErrTooManyPages is returned when a query has more pages than the client is configured to fetch.
+	ErrTooManyPages = errors.New("query result has more pages than allowed")
+	// ErrCircuitOpen is returned without calling the SpaceX API while the circuit breaker is open.
+	// It wraps ErrUnavailable.
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+)
+
+// ClientConfig holds the timeout, retry and circuit breaker settings of a SpaceXAPIClient.
+type ClientConfig struct {
+	// Timeout limits every single request, including reading the response body.
+	Timeout time.Duration
+	// MaxRetries is the number of times a request failing with a network error, a 5xx or a 429 status is retried.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry. It doubles with every retry, up to MaxRetryBackoff.
+	RetryBackoff time.Duration
+	// MaxRetryBackoff caps the delay between retries, including the delay asked for by a Retry-After header.
+	MaxRetryBackoff time.Duration
+	// BreakerThreshold is the number of consecutive failed calls that opens the circuit breaker, 0 disables it.
+	BreakerThreshold int
+	// BreakerCooldown is how long the open circuit breaker fails calls fast before letting a trial call through.
+	BreakerCooldown time.Duration
+	// MaxPages is the number of pages of a query result the client fetches at most, 0 removes the limit.
+	MaxPages int
+}
+
+// DefaultClientConfig returns the settings used by NewSpaceXAPIClient.
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		Timeout:          5 * time.Second,
+		MaxRetries:       2,
+		RetryBackoff:     200 * time.Millisecond,
+		MaxRetryBackoff:  2 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+		MaxPages:         10,
+	}
+}
+
 // SpaceXAPIClient represents a client for interacting with the SpaceX API.
 type SpaceXAPIClient struct {
-	Client  *http.Client
-	BaseURL string
+	Client          *http.Client
+	BaseURL         string
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	MaxPages        int
+	breaker         *circuitBreaker
 }
 
-// NewSpaceXAPIClient creates a new instance of SpaceXAPIClient with the specified base URL.
+// NewSpaceXAPIClient creates a new instance of SpaceXAPIClient with the specified base URL,
+// DefaultBaseURL when it is empty, and the DefaultClientConfig settings.
 func NewSpaceXAPIClient(baseURL string) *SpaceXAPIClient {
-	return &SpaceXAPIClient{
-		Client:  http.DefaultClient,
-		BaseURL: baseURL,
-	}
+	return NewSpaceXAPIClientWithConfig(baseURL, DefaultClientConfig())
 }
 
-// CheckScheduledLaunches checks if there are any launches scheduled for the given request body.
-func (c *SpaceXAPIClient) CheckScheduledLaunches(body models.RequestBody) (models.FilteredResponse, error) {
-	url := c.BaseURL + "launches/query"
-
-	var result models.FilteredResponse
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return result, fmt.Errorf("failed to marshal request body: %w", err)
+// NewSpaceXAPIClientWithConfig creates a new instance of SpaceXAPIClient with the specified base URL,
+// DefaultBaseURL when it is empty, and settings. The endpoint paths are appended to the base URL,
+// so it ends with a slash.
+func NewSpaceXAPIClientWithConfig(baseURL string, config ClientConfig) *SpaceXAPIClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
 	}
-
-	resp, err := c.Client.Post(url, "application/json", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return result, fmt.Errorf("failed to send request: %w", err)
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Printf("failed to close response body: %v", err)
-		}
-	}(resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		return result, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	return &SpaceXAPIClient{
+		Client:          &http.Client{Timeout: config.Timeout},
+		BaseURL:         baseURL,
+		MaxRetries:      config.MaxRetries,
+		RetryBackoff:    config.RetryBackoff,
+		MaxRetryBackoff: config.MaxRetryBackoff,
+		MaxPages:        config.MaxPages,
+		breaker:         newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
 	}
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return result, fmt.Errorf("failed to decode response body: %w", err)
+// CheckScheduledLaunches checks if there are any launches scheduled for the given request body, from every page
+// of the result. It returns ErrTooManyPages rather than a partial list when the result has more than MaxPages pages.
+func (c *SpaceXAPIClient) CheckScheduledLaunches(body models.RequestBody) (models.FilteredResponse, error) {
+	docs, pageInfo, err := queryAll[models.Filtered](c, "launches/query", body)
+	if err != nil {
+		return models.FilteredResponse{}, err
+	}
+	if pageInfo.HasNextPage {
+		return models.FilteredResponse{}, tooManyPages(pageInfo)
 	}
 
-	return result, nil
+	return models.FilteredResponse{Docs: docs, PageInfo: pageInfo}, nil
 }
 
 // CheckLaunchpadState checks launchpad state.
@@ -62,43 +118,151 @@ func (c *SpaceXAPIClient) CheckLaunchpadState(id string) (string, error) {
 	// FIXME: This endpoint does not support querying by status alone.
 	// Additionally, the launchpads/query endpoint does not allow filtering by launchpad ID.
 	// So this endpoint requests a single launchpad and returns its Status.
-	url := c.BaseURL + fmt.Sprintf("launchpads/%s", id)
-
-	resp, err := c.Client.Get(url)
+	data, err := c.send(http.MethodGet, fmt.Sprintf("launchpads/%s", id), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Printf("failed to close response body: %v", err)
-		}
-	}(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", err
 	}
 
 	var result models.Launchpad
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(data, &result); err != nil {
 		return "", fmt.Errorf("failed to decode response body: %w", err)
 	}
 
 	return result.Status, nil
 }
 
-// GetActiveLaunchpads gets launchpads in active state.
+// GetActiveLaunchpads gets launchpads in active state, from every page of the result.
+// It returns ErrTooManyPages rather than a partial list when the result has more than MaxPages pages.
 func (c *SpaceXAPIClient) GetActiveLaunchpads(body models.RequestBody) ([]models.Filtered, error) {
-	url := c.BaseURL + "launchpads/query"
+	return queryComplete[models.Filtered](c, "launchpads/query", body)
+}
+
+// GetLaunchpads gets the details of the launchpads matching the request body, from every page of the result.
+// It returns ErrTooManyPages rather than a partial list when the result has more than MaxPages pages.
+func (c *SpaceXAPIClient) GetLaunchpads(body models.RequestBody) ([]models.Launchpad, error) {
+	return queryComplete[models.Launchpad](c, "launchpads/query", body)
+}
+
+// GetLaunches gets the launches matching the request body, from every page of the result.
+// It returns ErrTooManyPages rather than a partial list when the result has more than MaxPages pages.
+func (c *SpaceXAPIClient) GetLaunches(body models.RequestBody) ([]models.Launch, error) {
+	return queryComplete[models.Launch](c, "launches/query", body)
+}
+
+// queryAll posts the request body to the query endpoint and collects the documents of the result page after
+// page, starting at the page of the body, until the last page or MaxPages pages. It returns the paging
+// metadata of the last fetched page, whose HasNextPage tells whether the documents are incomplete.
+func queryAll[T any](c *SpaceXAPIClient, path string, body models.RequestBody) ([]T, models.PageInfo, error) {
+	docs := []T{}
+	for pages := 1; ; pages++ {
+		var result struct {
+			Docs []T `json:"docs"`
+			models.PageInfo
+		}
+		if err := c.query(path, body, &result); err != nil {
+			return nil, models.PageInfo{}, err
+		}
+		docs = append(docs, result.Docs...)
+
+		// A next page that does not move forward would loop forever, so it ends the result as well.
+		if !result.HasNextPage || result.NextPage == nil || *result.NextPage <= result.Page {
+			return docs, result.PageInfo, nil
+		}
+		if c.MaxPages > 0 && pages >= c.MaxPages {
+			log.Printf("spacex query %s stopped after %d of %d pages", path, pages, result.TotalPages)
+			return docs, result.PageInfo, nil
+		}
+		body.Options.Page = *result.NextPage
+	}
+}
+
+// queryComplete is queryAll for callers that need every document of the result.
+func queryComplete[T any](c *SpaceXAPIClient, path string, body models.RequestBody) ([]T, error) {
+	docs, pageInfo, err := queryAll[T](c, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if pageInfo.HasNextPage {
+		return nil, tooManyPages(pageInfo)
+	}
+
+	return docs, nil
+}
 
+// tooManyPages returns the ErrTooManyPages error of a result whose last fetched page is described by pageInfo.
+func tooManyPages(pageInfo models.PageInfo) error {
+	return fmt.Errorf("%w: %d documents on %d pages", ErrTooManyPages, pageInfo.TotalDocs, pageInfo.TotalPages)
+}
+
+// query posts the request body to the query endpoint and decodes the response into result.
+func (c *SpaceXAPIClient) query(path string, body models.RequestBody, result interface{}) error {
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	resp, err := c.Client.Post(url, "application/json", bytes.NewBuffer(jsonBody))
+	data, err := c.send(http.MethodPost, path, jsonBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return err
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return nil
+}
+
+// send requests the path relative to the base URL and returns the body of the 200 response. Network errors,
+// 5xx and 429 responses are retried with exponential backoff; when the retries run out the call counts as
+// a failure for the circuit breaker and the error wraps ErrUnavailable. Other statuses are returned as is.
+func (c *SpaceXAPIClient) send(method, path string, body []byte) ([]byte, error) {
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	url := c.BaseURL + path
+	backoff := c.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		data, retryAfter, err := c.do(method, url, body)
+		if err == nil {
+			c.breaker.success()
+			return data, nil
+		}
+		if !errors.Is(err, ErrUnavailable) {
+			// The API answered, so it is up even if the request was refused.
+			c.breaker.success()
+			return nil, err
+		}
+		if attempt >= c.MaxRetries {
+			c.breaker.failure()
+			return nil, err
+		}
+
+		delay := max(retryAfter, jitter(backoff))
+		if c.MaxRetryBackoff > 0 {
+			delay = min(delay, c.MaxRetryBackoff)
+		}
+		log.Printf("spacex api request %s %s failed, retrying in %s: %v", method, url, delay, err)
+		time.Sleep(delay)
+		backoff *= 2
+	}
+}
+
+// do makes a single request. Failures worth retrying wrap ErrUnavailable, along with the delay
+// asked for by the Retry-After header of the response, if any.
+func (c *SpaceXAPIClient) do(method, url string, body []byte) ([]byte, time.Duration, error) {
+	request, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Client.Do(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: failed to send request: %v", ErrUnavailable, err)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -107,14 +271,36 @@ func (c *SpaceXAPIClient) GetActiveLaunchpads(body models.RequestBody) ([]models
 		}
 	}(resp.Body)
 
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, retryAfter(resp), fmt.Errorf("%w: unexpected status code: %d", ErrUnavailable, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: failed to read response body: %v", ErrUnavailable, err)
 	}
 
-	var result models.FilteredResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	return data, 0, nil
+}
+
+// retryAfter returns the delay of the Retry-After header given in seconds, or 0.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// jitter spreads the backoff between half and the full delay, so clients do not retry in lockstep.
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
 	}
 
-	return result.Docs, nil
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
 }
@@ -0,0 +1,323 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/fakespacex"
+)
+
+// testClientConfig keeps the retries of the tests fast.
+func testClientConfig() ClientConfig {
+	config := DefaultClientConfig()
+	config.Timeout = time.Second
+	config.RetryBackoff = time.Millisecond
+	config.MaxRetryBackoff = 5 * time.Millisecond
+
+	return config
+}
+
+// pagedFixtures returns launchpads pad-00 to pad-<launchpads-1> and launches from pad-00, one an hour
+// from 2026-11-02T00:00:00Z on, so the launches of that day span several default pages.
+func pagedFixtures(launchpads, launches int) fakespacex.Fixtures {
+	var fixtures fakespacex.Fixtures
+	for i := 0; i < launchpads; i++ {
+		fixtures.Launchpads = append(fixtures.Launchpads, map[string]interface{}{
+			"id":     fmt.Sprintf("pad-%02d", i),
+			"status": "active",
+		})
+	}
+	day := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < launches; i++ {
+		fixtures.Launches = append(fixtures.Launches, map[string]interface{}{
+			"id":        fmt.Sprintf("launch-%02d", i),
+			"launchpad": "pad-00",
+			"date_utc":  day.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+		})
+	}
+
+	return fixtures
+}
+
+// newFakeServer starts a fakespacex server with the fixtures and counts the requests it answers.
+func newFakeServer(t *testing.T, fixtures fakespacex.Fixtures) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	handler := fakespacex.NewHandler(fixtures)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestCheckScheduledLaunchesFollowsPages(t *testing.T) {
+	server, requests := newFakeServer(t, pagedFixtures(1, 24))
+	client := NewSpaceXAPIClientWithConfig(server.URL+"/v4/", testClientConfig())
+
+	result, err := client.CheckScheduledLaunches(launchesOn("pad-00"))
+	if err != nil {
+		t.Fatalf("CheckScheduledLaunches: %v", err)
+	}
+	if len(result.Docs) != 24 {
+		t.Errorf("got %d launches, want 24", len(result.Docs))
+	}
+	if result.HasNextPage || result.TotalDocs != 24 || result.TotalPages != 3 {
+		t.Errorf("page info = %+v, want the last of 3 pages of 24 launches", result.PageInfo)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("client sent %d requests, want one per page", got)
+	}
+
+	seen := make(map[string]bool, len(result.Docs))
+	for _, doc := range result.Docs {
+		if seen[doc.ID] {
+			t.Errorf("launch %s returned twice", doc.ID)
+		}
+		seen[doc.ID] = true
+	}
+}
+
+func TestCheckScheduledLaunchesFailsOnTooManyPages(t *testing.T) {
+	server, requests := newFakeServer(t, pagedFixtures(1, 24))
+	config := testClientConfig()
+	config.MaxPages = 2
+	client := NewSpaceXAPIClientWithConfig(server.URL+"/v4/", config)
+
+	result, err := client.CheckScheduledLaunches(launchesOn("pad-00"))
+	if !errors.Is(err, ErrTooManyPages) {
+		t.Fatalf("got error %v, want ErrTooManyPages", err)
+	}
+	if errors.Is(err, ErrUnavailable) {
+		t.Error("ErrTooManyPages must not be mistaken for an unavailable API")
+	}
+	if len(result.Docs) != 0 {
+		t.Errorf("got %d launches, want none instead of a partial list", len(result.Docs))
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("client sent %d requests, want MaxPages", got)
+	}
+}
+
+func TestCheckScheduledLaunchesWithoutPageLimit(t *testing.T) {
+	server, requests := newFakeServer(t, pagedFixtures(1, 24))
+	config := testClientConfig()
+	config.MaxPages = 0
+	client := NewSpaceXAPIClientWithConfig(server.URL+"/v4/", config)
+
+	body := NewQuery(Eq("launchpad", "pad-00")).Select("id").Limit(2).Body()
+	result, err := client.CheckScheduledLaunches(body)
+	if err != nil {
+		t.Fatalf("CheckScheduledLaunches: %v", err)
+	}
+	if len(result.Docs) != 24 || requests.Load() != 12 {
+		t.Errorf("got %d launches in %d requests, want 24 in 12", len(result.Docs), requests.Load())
+	}
+}
+
+func TestCheckScheduledLaunchesStartsAtTheQueriedPage(t *testing.T) {
+	server, _ := newFakeServer(t, pagedFixtures(1, 24))
+	client := NewSpaceXAPIClientWithConfig(server.URL+"/v4/", testClientConfig())
+
+	body := NewQuery(Eq("launchpad", "pad-00")).Select("id").Sort("date_utc").Page(2).Body()
+	result, err := client.CheckScheduledLaunches(body)
+	if err != nil {
+		t.Fatalf("CheckScheduledLaunches: %v", err)
+	}
+	if len(result.Docs) != 14 || result.Docs[0].ID != "launch-10" {
+		t.Errorf("got %d launches starting at %v, want the 14 launches from launch-10 on", len(result.Docs), result.Docs)
+	}
+}
+
+func TestGetActiveLaunchpadsFailsOnTooManyPages(t *testing.T) {
+	server, _ := newFakeServer(t, pagedFixtures(25, 0))
+	config := testClientConfig()
+	config.MaxPages = 2
+	client := NewSpaceXAPIClientWithConfig(server.URL+"/v4/", config)
+
+	body := NewQuery(Eq("status", "active")).Select("id").Body()
+	if _, err := client.GetActiveLaunchpads(body); !errors.Is(err, ErrTooManyPages) {
+		t.Fatalf("got error %v, want ErrTooManyPages", err)
+	}
+
+	config.MaxPages = 3
+	client = NewSpaceXAPIClientWithConfig(server.URL+"/v4/", config)
+	launchpads, err := client.GetActiveLaunchpads(body)
+	if err != nil {
+		t.Fatalf("GetActiveLaunchpads: %v", err)
+	}
+	if len(launchpads) != 25 {
+		t.Errorf("got %d launchpads, want 25", len(launchpads))
+	}
+}
+
+// scriptedServer answers the requests with the statuses in order, then with 200 and the launchpad
+// acme-1. It counts the requests it answered.
+func scriptedServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		if n <= len(statuses) && statuses[n-1] != http.StatusOK {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "acme-1", "status": "active"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestSendRetriesServerErrors(t *testing.T) {
+	server, requests := scriptedServer(t, nil, http.StatusBadGateway, http.StatusServiceUnavailable)
+	client := NewSpaceXAPIClientWithConfig(server.URL, testClientConfig())
+
+	state, err := client.CheckLaunchpadState("acme-1")
+	if err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if state != "active" {
+		t.Errorf("got state %q, want active", state)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("server answered %d requests, want 2 failures and the successful retry", got)
+	}
+}
+
+func TestSendWaitsForRetryAfter(t *testing.T) {
+	server, requests := scriptedServer(t, http.Header{"Retry-After": []string{"1"}}, http.StatusTooManyRequests)
+	config := testClientConfig()
+	config.MaxRetryBackoff = 2 * time.Second
+	client := NewSpaceXAPIClientWithConfig(server.URL, config)
+
+	start := time.Now()
+	if _, err := client.CheckLaunchpadState("acme-1"); err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want the second of Retry-After", elapsed)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server answered %d requests, want 2", got)
+	}
+}
+
+func TestSendCapsRetryAfter(t *testing.T) {
+	server, _ := scriptedServer(t, http.Header{"Retry-After": []string{"60"}}, http.StatusTooManyRequests)
+	client := NewSpaceXAPIClientWithConfig(server.URL, testClientConfig())
+
+	start := time.Now()
+	if _, err := client.CheckLaunchpadState("acme-1"); err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("retried after %s, want at most MaxRetryBackoff", elapsed)
+	}
+}
+
+func TestSendGivesUpAfterRetries(t *testing.T) {
+	server, requests := scriptedServer(t, nil, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusInternalServerError)
+	client := NewSpaceXAPIClientWithConfig(server.URL, testClientConfig())
+
+	_, err := client.CheckLaunchpadState("acme-1")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got error %v, want ErrUnavailable", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("server answered %d requests, want the request and MaxRetries retries", got)
+	}
+}
+
+func TestSendDoesNotRetryClientErrors(t *testing.T) {
+	server, requests := scriptedServer(t, nil, http.StatusNotFound)
+	client := NewSpaceXAPIClientWithConfig(server.URL, testClientConfig())
+
+	_, err := client.CheckLaunchpadState("acme-1")
+	if err == nil || errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got error %v, want a plain status error", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server answered %d requests, want 1", got)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	config := testClientConfig()
+	config.MaxRetries = 0
+	config.BreakerThreshold = 3
+	config.BreakerCooldown = 50 * time.Millisecond
+	failures := make([]int, config.BreakerThreshold+1)
+	for i := range failures {
+		failures[i] = http.StatusInternalServerError
+	}
+	server, requests := scriptedServer(t, nil, failures...)
+	client := NewSpaceXAPIClientWithConfig(server.URL, config)
+
+	for i := 0; i < config.BreakerThreshold; i++ {
+		if _, err := client.CheckLaunchpadState("acme-1"); !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: got error %v, want a failed call", i+1, err)
+		}
+	}
+
+	// The breaker is open: calls fail fast without reaching the server.
+	for i := 0; i < 3; i++ {
+		_, err := client.CheckLaunchpadState("acme-1")
+		if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("got error %v, want ErrCircuitOpen wrapping ErrUnavailable", err)
+		}
+	}
+	if got := int(requests.Load()); got != config.BreakerThreshold {
+		t.Fatalf("server answered %d requests, want %d", got, config.BreakerThreshold)
+	}
+
+	// After the cooldown the failing trial call opens the breaker again.
+	time.Sleep(2 * config.BreakerCooldown)
+	if _, err := client.CheckLaunchpadState("acme-1"); errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got error %v, want the failed trial call", err)
+	}
+	if _, err := client.CheckLaunchpadState("acme-1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got error %v, want ErrCircuitOpen after the failed trial call", err)
+	}
+
+	// The next trial call succeeds and closes the breaker.
+	time.Sleep(2 * config.BreakerCooldown)
+	for i := 0; i < 2; i++ {
+		if _, err := client.CheckLaunchpadState("acme-1"); err != nil {
+			t.Fatalf("call %d after recovery: %v", i+1, err)
+		}
+	}
+	if got := int(requests.Load()); got != config.BreakerThreshold+3 {
+		t.Errorf("server answered %d requests, want %d", got, config.BreakerThreshold+3)
+	}
+}
+
+func TestCircuitBreakerResetsOnSuccess(t *testing.T) {
+	config := testClientConfig()
+	config.MaxRetries = 0
+	config.BreakerThreshold = 2
+	server, requests := scriptedServer(t, nil, http.StatusInternalServerError, http.StatusOK, http.StatusInternalServerError)
+	client := NewSpaceXAPIClientWithConfig(server.URL, config)
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.CheckLaunchpadState("acme-1"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: breaker opened without %d consecutive failures", i+1, config.BreakerThreshold)
+		}
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("server answered %d requests, want 4", got)
+	}
+}
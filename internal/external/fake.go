@@ -0,0 +1,204 @@
+package external
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// FakeLaunch is a launch known to a FakeLaunchProvider.
+type FakeLaunch struct {
+	ID          string
+	LaunchpadID string
+	DateUTC     time.Time
+}
+
+// FakeLaunchProvider is an in-memory LaunchProvider for tests and local development.
+// It understands the queries built by the booking service and the schedule generator:
+// launches by "launchpad" and a "date_utc" range, and launchpads by "status".
+// It is safe for concurrent use.
+type FakeLaunchProvider struct {
+	mu         sync.RWMutex
+	launchpads map[string]models.Launchpad
+	launches   []FakeLaunch
+	err        error
+}
+
+// NewFakeLaunchProvider creates an empty FakeLaunchProvider.
+func NewFakeLaunchProvider() *FakeLaunchProvider {
+	return &FakeLaunchProvider{
+		launchpads: make(map[string]models.Launchpad),
+	}
+}
+
+// AddLaunchpad adds a launchpad or replaces the launchpad with the same ID.
+func (f *FakeLaunchProvider) AddLaunchpad(launchpad models.Launchpad) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.launchpads[launchpad.ID] = launchpad
+}
+
+// AddLaunch adds a planned launch.
+func (f *FakeLaunchProvider) AddLaunch(launch FakeLaunch) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.launches = append(f.launches, launch)
+}
+
+// SetError makes every call fail with err, simulating an unavailable provider. A nil err restores the normal behaviour.
+func (f *FakeLaunchProvider) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+}
+
+// CheckScheduledLaunches returns the launches from the queried launchpad within the queried date_utc range.
+func (f *FakeLaunchProvider) CheckScheduledLaunches(body models.RequestBody) (models.FilteredResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	result := models.FilteredResponse{Docs: []models.Filtered{}}
+	if f.err != nil {
+		return result, f.err
+	}
+
+	var launchpadID string
+	var from, to *time.Time
+	for field, value := range body.Query {
+		var err error
+		switch field {
+		case "launchpad":
+			launchpadID, err = stringValue(field, value)
+		case "date_utc":
+			from, to, err = dateRange(value)
+		default:
+			err = fmt.Errorf("unsupported query field %q", field)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, launch := range f.launches {
+		if launchpadID != "" && launch.LaunchpadID != launchpadID {
+			continue
+		}
+		if from != nil && launch.DateUTC.Before(*from) {
+			continue
+		}
+		if to != nil && !launch.DateUTC.Before(*to) {
+			continue
+		}
+		result.Docs = append(result.Docs, models.Filtered{ID: launch.ID})
+	}
+	// Every launch fits on a single page.
+	result.PageInfo = models.PageInfo{TotalDocs: len(result.Docs), Limit: len(result.Docs), Page: 1, TotalPages: 1}
+
+	return result, nil
+}
+
+// CheckLaunchpadState returns the status of the launchpad.
+func (f *FakeLaunchProvider) CheckLaunchpadState(id string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.err != nil {
+		return "", f.err
+	}
+
+	launchpad, ok := f.launchpads[id]
+	if !ok {
+		return "", fmt.Errorf("launchpad %s not found", id)
+	}
+
+	return launchpad.Status, nil
+}
+
+// GetActiveLaunchpads returns the launchpads with the queried status, ordered by ID.
+func (f *FakeLaunchProvider) GetActiveLaunchpads(body models.RequestBody) ([]models.Filtered, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var status string
+	for field, value := range body.Query {
+		var err error
+		switch field {
+		case "status":
+			status, err = stringValue(field, value)
+		default:
+			err = fmt.Errorf("unsupported query field %q", field)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var launchpads []models.Filtered
+	for _, launchpad := range f.launchpads {
+		if status == "" || launchpad.Status == status {
+			launchpads = append(launchpads, models.Filtered{ID: launchpad.ID})
+		}
+	}
+	sort.Slice(launchpads, func(i, j int) bool {
+		return launchpads[i].ID < launchpads[j].ID
+	})
+
+	return launchpads, nil
+}
+
+// stringValue returns the value of an equality condition on a string field.
+func stringValue(field string, value interface{}) (string, error) {
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("unsupported value of query field %q: %v", field, value)
+	}
+
+	return str, nil
+}
+
+// dateRange returns the bounds of a {"$gte": from, "$lt": to} condition on RFC 3339 dates.
+func dateRange(value interface{}) (from, to *time.Time, err error) {
+	var bounds map[string]string
+	switch v := value.(type) {
+	case map[string]string:
+		bounds = v
+	case map[string]interface{}:
+		bounds = make(map[string]string, len(v))
+		for operator, bound := range v {
+			str, ok := bound.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("unsupported date_utc bound %v", bound)
+			}
+			bounds[operator] = str
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported value of query field %q: %v", "date_utc", value)
+	}
+
+	for operator, bound := range bounds {
+		date, err := time.Parse(time.RFC3339, bound)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date_utc bound %q: %w", bound, err)
+		}
+		switch operator {
+		case "$gte":
+			from = &date
+		case "$lt":
+			to = &date
+		default:
+			return nil, nil, fmt.Errorf("unsupported date_utc operator %q", operator)
+		}
+	}
+
+	return from, to, nil
+}
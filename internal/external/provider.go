@@ -0,0 +1,22 @@
+package external
+
+import "github.com/klemis/go-spaceflight-booking-api/models"
+
+// LaunchProvider provides the launch and launchpad data bookings and schedules depend on.
+type LaunchProvider interface {
+	// CheckScheduledLaunches returns the launches matching the query of the request body.
+	CheckScheduledLaunches(body models.RequestBody) (models.FilteredResponse, error)
+	// CheckLaunchpadState returns the status of the launchpad, such as "active" or "retired".
+	CheckLaunchpadState(id string) (string, error)
+	// GetActiveLaunchpads returns the launchpads matching the query of the request body.
+	GetActiveLaunchpads(body models.RequestBody) ([]models.Filtered, error)
+}
+
+// SpaceXAPIClient talks to the SpaceX API over HTTP.
+var _ LaunchProvider = (*SpaceXAPIClient)(nil)
+
+// CachedLaunchProvider caches the lookups of another LaunchProvider.
+var _ LaunchProvider = (*CachedLaunchProvider)(nil)
+
+// StaticLaunchProvider reads the launches of an operator from a file.
+var _ LaunchProvider = (*StaticLaunchProvider)(nil)
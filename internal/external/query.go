@@ -0,0 +1,147 @@
+package external
+
+import (
+	"strings"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// Condition is a part of the filter of a SpaceX query, in the mongoose query language.
+type Condition map[string]interface{}
+
+// Eq matches the documents whose field equals the value, or contains it when the field is an array.
+func Eq(field string, value interface{}) Condition {
+	return Condition{field: value}
+}
+
+// In matches the documents whose field equals one of the values.
+func In(field string, values ...interface{}) Condition {
+	return Condition{field: map[string]interface{}{"$in": values}}
+}
+
+// Range matches the documents whose field is at least gte and less than lt. A nil bound is left out.
+func Range(field string, gte, lt interface{}) Condition {
+	operators := map[string]interface{}{}
+	if gte != nil {
+		operators["$gte"] = gte
+	}
+	if lt != nil {
+		operators["$lt"] = lt
+	}
+
+	return Condition{field: operators}
+}
+
+// And matches the documents matching every condition.
+func And(conditions ...Condition) Condition {
+	return Condition{"$and": conditions}
+}
+
+// Or matches the documents matching any of the conditions.
+func Or(conditions ...Condition) Condition {
+	return Condition{"$or": conditions}
+}
+
+// Query builds the request body of the query endpoints of the SpaceX API:
+//
+//	body := external.NewQuery(external.Eq("launchpad", id), external.Range("date_utc", gte, lt)).Select("id").Body()
+type Query struct {
+	filter  map[string]interface{}
+	options models.Options
+}
+
+// NewQuery creates a query matching the documents that match every condition.
+func NewQuery(conditions ...Condition) *Query {
+	q := &Query{filter: map[string]interface{}{}}
+	for _, condition := range conditions {
+		q.Where(condition)
+	}
+
+	return q
+}
+
+// Where adds a condition to the filter. Operators on a field already filtered by an operator are merged,
+// so Range and In conditions on the same field combine; otherwise the condition replaces the former one.
+// The operators are merged into a copy, the conditions passed by the caller are never changed.
+func (q *Query) Where(condition Condition) *Query {
+	for field, value := range condition {
+		existing, existingOK := q.filter[field].(map[string]interface{})
+		operators, ok := value.(map[string]interface{})
+		if existingOK && ok {
+			merged := make(map[string]interface{}, len(existing)+len(operators))
+			for operator, operand := range existing {
+				merged[operator] = operand
+			}
+			for operator, operand := range operators {
+				merged[operator] = operand
+			}
+			q.filter[field] = merged
+			continue
+		}
+		q.filter[field] = value
+	}
+
+	return q
+}
+
+// Select only returns the fields of the documents.
+func (q *Query) Select(fields ...string) *Query {
+	if q.options.Select == nil {
+		q.options.Select = map[string]int{}
+	}
+	for _, field := range fields {
+		q.options.Select[field] = 1
+	}
+
+	return q
+}
+
+// Populate replaces the ID in the path with the referenced document, restricted to the fields when any are given.
+func (q *Query) Populate(path string, fields ...string) *Query {
+	option := models.PopulateOption{Path: path}
+	if len(fields) > 0 {
+		option.Select = map[string]int{}
+		for _, field := range fields {
+			option.Select[field] = 1
+		}
+	}
+	q.options.Populate = append(q.options.Populate, option)
+
+	return q
+}
+
+// Sort orders the documents by the fields, descending for fields prefixed with "-".
+func (q *Query) Sort(fields ...string) *Query {
+	q.options.Sort = strings.TrimSpace(q.options.Sort + " " + strings.Join(fields, " "))
+
+	return q
+}
+
+// Limit sets the number of documents of a page.
+func (q *Query) Limit(limit int) *Query {
+	q.options.Limit = limit
+
+	return q
+}
+
+// Page selects the page of documents, starting at 1.
+func (q *Query) Page(page int) *Query {
+	q.options.Page = page
+
+	return q
+}
+
+// Pagination disables the pagination when false, returning every matching document at once.
+func (q *Query) Pagination(enabled bool) *Query {
+	q.options.Pagination = &enabled
+
+	return q
+}
+
+// Body returns the request body of the query.
+func (q *Query) Body() models.RequestBody {
+	return models.RequestBody{
+		Query:   q.filter,
+		Options: q.options,
+	}
+}
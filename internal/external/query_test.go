@@ -0,0 +1,172 @@
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+const (
+	gte = "2026-11-02T00:00:00Z"
+	lt  = "2026-11-03T00:00:00Z"
+)
+
+// goldenQueries are the request bodies sent by the service and the binaries, with the hand-built maps
+// the builder replaced. The marshalled bodies must stay identical, byte for byte.
+var goldenQueries = []struct {
+	name   string
+	query  *Query
+	legacy models.RequestBody
+}{
+	{
+		// service.prepareRequestBody, the launches from a launchpad on a day.
+		name:  "launches_on_day",
+		query: NewQuery(Eq("launchpad", "5e9e4501f5090910d4566f83"), Range("date_utc", gte, lt)).Select("id"),
+		legacy: models.RequestBody{
+			Query: map[string]interface{}{
+				"launchpad": "5e9e4501f5090910d4566f83",
+				"date_utc": map[string]string{
+					"$gte": gte,
+					"$lt":  lt,
+				},
+			},
+			Options: models.Options{
+				Select: map[string]int{
+					"id": 1,
+				},
+			},
+		},
+	},
+	{
+		// The active launchpads of cmd/schedule.
+		name:  "active_launchpads",
+		query: NewQuery(Eq("status", "active")).Select("id"),
+		legacy: models.RequestBody{
+			Query: map[string]interface{}{
+				"status": "active",
+			},
+			Options: models.Options{
+				Select: map[string]int{
+					"id": 1,
+				},
+			},
+		},
+	},
+	{
+		// The upcoming launches of cmd/sync, without pagination.
+		name:  "upcoming_launches",
+		query: NewQuery(Eq("upcoming", true)).Pagination(false),
+		legacy: models.RequestBody{
+			Query: map[string]interface{}{
+				"upcoming": true,
+			},
+			Options: models.Options{
+				Pagination: func() *bool { pagination := false; return &pagination }(),
+			},
+		},
+	},
+}
+
+func TestQueryGolden(t *testing.T) {
+	for _, tc := range goldenQueries {
+		t.Run(tc.name, func(t *testing.T) {
+			got := marshal(t, tc.query.Body())
+			legacy := marshal(t, tc.legacy)
+			if !bytes.Equal(got, legacy) {
+				t.Errorf("body differs from the hand-built one\n got: %s\nwant: %s", got, legacy)
+			}
+			assertGolden(t, tc.name, got)
+		})
+	}
+}
+
+func TestQueryOptions(t *testing.T) {
+	query := NewQuery(
+		In("status", "active", "under construction"),
+		Or(Eq("upcoming", true), And(Eq("success", true), Range("date_utc", gte, nil))),
+	).
+		Select("name", "date_utc").
+		Populate("launchpad", "name", "status").
+		Populate("rocket").
+		Sort("-date_utc").
+		Sort("name").
+		Limit(50).
+		Page(2)
+
+	assertGolden(t, "options", marshal(t, query.Body()))
+}
+
+func TestQueryWhereMergesOperators(t *testing.T) {
+	from := Range("date_utc", gte, nil)
+	to := Range("date_utc", nil, lt)
+	body := NewQuery(from, to).Body()
+
+	want := map[string]interface{}{"$gte": gte, "$lt": lt}
+	if got := body.Query["date_utc"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("date_utc = %v, want %v", got, want)
+	}
+	if got := from["date_utc"]; !reflect.DeepEqual(got, map[string]interface{}{"$gte": gte}) {
+		t.Errorf("first condition was changed to %v", got)
+	}
+	if got := to["date_utc"]; !reflect.DeepEqual(got, map[string]interface{}{"$lt": lt}) {
+		t.Errorf("second condition was changed to %v", got)
+	}
+}
+
+func TestQueryWhereReplacesValues(t *testing.T) {
+	statuses := In("status", "active")
+	body := NewQuery(statuses, Eq("status", "retired")).Body()
+
+	if got := body.Query["status"]; got != "retired" {
+		t.Errorf("status = %v, want retired", got)
+	}
+	if got := statuses["status"]; !reflect.DeepEqual(got, map[string]interface{}{"$in": []interface{}{"active"}}) {
+		t.Errorf("condition was changed to %v", got)
+	}
+}
+
+// marshal returns the JSON request body sent to the SpaceX API.
+func marshal(t *testing.T, body models.RequestBody) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	return data
+}
+
+// assertGolden compares the JSON with testdata/<name>.golden.json, rewriting the file with -update.
+func assertGolden(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		t.Fatalf("failed to indent body: %v", err)
+	}
+	indented.WriteByte('\n')
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(indented.Bytes(), want) {
+		t.Errorf("body differs from %s\n got: %s\nwant: %s", path, indented.Bytes(), want)
+	}
+}
@@ -0,0 +1,95 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SpaceXProvider is the name of the SpaceX launch provider, the provider of the schedules,
+// flights and bookings created before launch providers were introduced.
+const SpaceXProvider = "spacex"
+
+// ErrUnknownProvider is returned when no launch provider is registered under a name.
+var ErrUnknownProvider = errors.New("unknown launch provider")
+
+// Registry holds the launch providers of the launch operators by name. Every launchpad belongs
+// to one operator, whose provider lists its launchpads, reports their status and checks their launches.
+// It is not safe for concurrent registration; register the providers before serving requests.
+type Registry struct {
+	providers   map[string]LaunchProvider
+	defaultName string
+}
+
+// NewRegistry creates an empty Registry whose default provider, used when none is given, is defaultName.
+func NewRegistry(defaultName string) *Registry {
+	return &Registry{
+		providers:   make(map[string]LaunchProvider),
+		defaultName: defaultName,
+	}
+}
+
+// Register adds the provider under the name, which must be unique.
+func (r *Registry) Register(name string, provider LaunchProvider) error {
+	if name == "" {
+		return fmt.Errorf("launch provider name must not be empty")
+	}
+	if _, ok := r.providers[name]; ok {
+		return fmt.Errorf("launch provider %q is already registered", name)
+	}
+	r.providers[name] = provider
+
+	return nil
+}
+
+// Get returns the provider registered under the name. It returns ErrUnknownProvider if there is none.
+func (r *Registry) Get(name string) (LaunchProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+
+	return provider, nil
+}
+
+// Default returns the name of the default provider.
+func (r *Registry) Default() string {
+	return r.defaultName
+}
+
+// Names returns the names of the registered providers in alphabetical order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// RegisterStaticFiles registers a StaticLaunchProvider for every "name=path" entry of the comma-separated spec,
+// such as "acme=/etc/launches/acme.json,orbital=/etc/launches/orbital.json". An empty spec registers nothing.
+func (r *Registry) RegisterStaticFiles(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, path, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || path == "" {
+			return fmt.Errorf("invalid static launch provider %q, expected name=path", entry)
+		}
+		provider, err := NewStaticLaunchProvider(path)
+		if err != nil {
+			return fmt.Errorf("failed to load launch provider %q: %w", name, err)
+		}
+		if err := r.Register(name, provider); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// staticFile is the content of the file of a StaticLaunchProvider.
+type staticFile struct {
+	Launchpads []models.Launchpad `json:"launchpads"`
+	Launches   []models.Launch    `json:"launches"`
+}
+
+// StaticLaunchProvider is the LaunchProvider of a launch operator publishing its launchpads and launches as a file
+// instead of an API. The file is a JSON object with the "launchpads" in the format of models.Launchpad and the
+// "launches" in the format of models.Launch. It is read once, restart to pick up changes.
+// It answers the same queries as the FakeLaunchProvider it is built on.
+type StaticLaunchProvider struct {
+	launches *FakeLaunchProvider
+}
+
+// NewStaticLaunchProvider creates a StaticLaunchProvider from the file at path.
+func NewStaticLaunchProvider(path string) (*StaticLaunchProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read launch file: %w", err)
+	}
+
+	var file staticFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode launch file %s: %w", path, err)
+	}
+
+	launches := NewFakeLaunchProvider()
+	for _, launchpad := range file.Launchpads {
+		if launchpad.ID == "" {
+			return nil, fmt.Errorf("launchpad %q of %s has no id", launchpad.Name, path)
+		}
+		launches.AddLaunchpad(launchpad)
+	}
+	for _, launch := range file.Launches {
+		launches.AddLaunch(FakeLaunch{ID: launch.ID, LaunchpadID: launch.LaunchpadID, DateUTC: launch.DateUTC})
+	}
+
+	return &StaticLaunchProvider{launches: launches}, nil
+}
+
+// CheckScheduledLaunches returns the launches of the file from the queried launchpad within the queried date_utc range.
+func (p *StaticLaunchProvider) CheckScheduledLaunches(body models.RequestBody) (models.FilteredResponse, error) {
+	return p.launches.CheckScheduledLaunches(body)
+}
+
+// CheckLaunchpadState returns the status of the launchpad of the file.
+func (p *StaticLaunchProvider) CheckLaunchpadState(id string) (string, error) {
+	return p.launches.CheckLaunchpadState(id)
+}
+
+// GetActiveLaunchpads returns the launchpads of the file with the queried status.
+func (p *StaticLaunchProvider) GetActiveLaunchpads(body models.RequestBody) ([]models.Filtered, error) {
+	return p.launches.GetActiveLaunchpads(body)
+}
@@ -0,0 +1,121 @@
+// Package fakespacex serves launchpads and launches from fixtures like the SpaceX API v4, for offline
+// development and tests. The handler works with net/http/httptest:
+//
+//	server := httptest.NewServer(fakespacex.NewHandler(fakespacex.DefaultFixtures()))
+//	defer server.Close()
+//	client := external.NewSpaceXAPIClient(server.URL + "/v4/")
+package fakespacex
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+)
+
+//go:embed fixtures/*.json
+var defaultFixtures embed.FS
+
+// Fixtures holds the documents served by the handler, decoded from JSON as the SpaceX API returns them.
+type Fixtures struct {
+	Launchpads []map[string]interface{}
+	Launches   []map[string]interface{}
+}
+
+// DefaultFixtures returns the fixtures bundled with the package.
+func DefaultFixtures() Fixtures {
+	sub, err := fs.Sub(defaultFixtures, "fixtures")
+	if err != nil {
+		log.Fatalf("failed to open bundled fixtures: %v", err)
+	}
+
+	fixtures, err := loadFixtures(sub)
+	if err != nil {
+		log.Fatalf("failed to load bundled fixtures: %v", err)
+	}
+
+	return fixtures
+}
+
+// LoadFixtures reads launchpads.json and launches.json from the directory. Both hold a JSON array of documents.
+func LoadFixtures(dir string) (Fixtures, error) {
+	return loadFixtures(os.DirFS(dir))
+}
+
+func loadFixtures(fsys fs.FS) (Fixtures, error) {
+	var fixtures Fixtures
+	files := map[string]*[]map[string]interface{}{
+		"launchpads.json": &fixtures.Launchpads,
+		"launches.json":   &fixtures.Launches,
+	}
+	for name, docs := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return Fixtures{}, fmt.Errorf("failed to read fixture %s: %w", name, err)
+		}
+		if err := json.Unmarshal(data, docs); err != nil {
+			return Fixtures{}, fmt.Errorf("failed to decode fixture %s: %w", name, err)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// NewHandler returns a handler serving the fixtures under /v4/ with the endpoints used by the SpaceX client:
+// POST launchpads/query, GET launchpads/:id, POST launches/query and GET launches/:id.
+func NewHandler(fixtures Fixtures) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v4/launchpads/query", queryHandler(fixtures.Launchpads))
+	mux.HandleFunc("GET /v4/launchpads/{id}", getHandler(fixtures.Launchpads))
+	mux.HandleFunc("POST /v4/launches/query", queryHandler(fixtures.Launches))
+	mux.HandleFunc("GET /v4/launches/{id}", getHandler(fixtures.Launches))
+
+	return mux
+}
+
+// queryHandler answers a mongoose-style query with a page of the matching documents.
+func queryHandler(docs []map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body queryBody
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+				return
+			}
+		}
+
+		page, err := runQuery(docs, body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// getHandler answers with the document with the ID of the path, or 404.
+func getHandler(docs []map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		for _, doc := range docs {
+			if doc["id"] == id {
+				writeJSON(w, http.StatusOK, doc)
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Not Found"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package fakespacex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// newClient starts a server with the fixtures and returns a client of it, along with the number of requests it answered.
+func newClient(t *testing.T, fixtures Fixtures) (*external.SpaceXAPIClient, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	handler := NewHandler(fixtures)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	config := external.DefaultClientConfig()
+	config.MaxRetries = 0
+
+	return external.NewSpaceXAPIClientWithConfig(server.URL+"/v4/", config), &requests
+}
+
+// testFixtures returns the bundled fixtures with the details of STLS removed, so $exists has a missing field to find.
+func testFixtures() Fixtures {
+	fixtures := DefaultFixtures()
+	for _, launchpad := range fixtures.Launchpads {
+		if launchpad["name"] == "STLS" {
+			delete(launchpad, "details")
+		}
+	}
+
+	return fixtures
+}
+
+// operator returns the condition applying the mongoose operator to the field.
+func operator(field, op string, operand interface{}) external.Condition {
+	return external.Condition{field: map[string]interface{}{op: operand}}
+}
+
+func names(launchpads []models.Launchpad) []string {
+	result := make([]string, 0, len(launchpads))
+	for _, launchpad := range launchpads {
+		result = append(result, launchpad.Name)
+	}
+
+	return result
+}
+
+func TestQueryOperators(t *testing.T) {
+	client, _ := newClient(t, testFixtures())
+
+	tests := []struct {
+		name      string
+		condition external.Condition
+		want      []string
+	}{
+		{"equality", external.Eq("status", "active"), []string{"CCSFS SLC 40", "KSC LC 39A", "VAFB SLC 4E"}},
+		{"eq", operator("region", "$eq", "Florida"), []string{"CCSFS SLC 40", "KSC LC 39A"}},
+		{"ne", operator("status", "$ne", "active"), []string{"Kwajalein Atoll", "STLS", "VAFB SLC 3W"}},
+		{"gt", operator("launch_attempts", "$gt", 15), []string{"CCSFS SLC 40", "KSC LC 39A"}},
+		{"gte", operator("launch_attempts", "$gte", 15), []string{"CCSFS SLC 40", "KSC LC 39A", "VAFB SLC 4E"}},
+		{"lt", operator("launch_attempts", "$lt", 5), []string{"STLS", "VAFB SLC 3W"}},
+		{"lte", operator("launch_attempts", "$lte", 5), []string{"Kwajalein Atoll", "STLS", "VAFB SLC 3W"}},
+		{"in", external.In("region", "Texas", "Marshall Islands"), []string{"Kwajalein Atoll", "STLS"}},
+		{"nin", operator("region", "$nin", []string{"Florida", "California"}), []string{"Kwajalein Atoll", "STLS"}},
+		{"exists", operator("details", "$exists", false), []string{"STLS"}},
+		{"array element", external.Eq("rockets", "5e9d0d95eda69974db09d1ed"), []string{"KSC LC 39A"}},
+		{"dotted field", operator("images.large", "$exists", true), []string{"CCSFS SLC 40", "KSC LC 39A", "Kwajalein Atoll", "STLS", "VAFB SLC 3W", "VAFB SLC 4E"}},
+		{"and", external.And(external.Eq("region", "California"), external.Eq("status", "active")), []string{"VAFB SLC 4E"}},
+		{"or", external.Or(external.Eq("region", "Texas"), operator("launch_attempts", "$gte", 55)), []string{"CCSFS SLC 40", "KSC LC 39A", "STLS"}},
+		{"nor", external.Condition{"$nor": []external.Condition{external.Eq("status", "active"), external.Eq("region", "Texas")}}, []string{"Kwajalein Atoll", "VAFB SLC 3W"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			launchpads, err := client.GetLaunchpads(external.NewQuery(tc.condition).Sort("name").Body())
+			if err != nil {
+				t.Fatalf("GetLaunchpads: %v", err)
+			}
+			if got := names(launchpads); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQueryRejectsUnsupportedOperators(t *testing.T) {
+	client, _ := newClient(t, testFixtures())
+
+	_, err := client.GetLaunchpads(external.NewQuery(operator("name", "$regex", "^VAFB")).Body())
+	if err == nil {
+		t.Fatal("got no error for an unsupported operator")
+	}
+}
+
+func TestQuerySelect(t *testing.T) {
+	client, _ := newClient(t, testFixtures())
+
+	launchpads, err := client.GetLaunchpads(external.NewQuery(external.Eq("name", "KSC LC 39A")).Select("name").Body())
+	if err != nil {
+		t.Fatalf("GetLaunchpads: %v", err)
+	}
+	want := []models.Launchpad{{ID: "5e9e4502f509094188566f88", Name: "KSC LC 39A"}}
+	if !reflect.DeepEqual(launchpads, want) {
+		t.Errorf("got %+v, want only the id and name %+v", launchpads, want)
+	}
+}
+
+func TestQuerySort(t *testing.T) {
+	client, _ := newClient(t, testFixtures())
+
+	launchpads, err := client.GetLaunchpads(external.NewQuery().Sort("-launch_attempts").Body())
+	if err != nil {
+		t.Fatalf("GetLaunchpads: %v", err)
+	}
+	// Launchpads with the same number of attempts keep their fixture order.
+	want := []string{"CCSFS SLC 40", "KSC LC 39A", "VAFB SLC 4E", "Kwajalein Atoll", "VAFB SLC 3W", "STLS"}
+	if got := names(launchpads); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	launches, err := client.GetLaunches(external.NewQuery().Sort("launchpad", "-date_utc").Body())
+	if err != nil {
+		t.Fatalf("GetLaunches: %v", err)
+	}
+	var got []string
+	for _, launch := range launches {
+		got = append(got, launch.Name)
+	}
+	want = []string{"Starlink Group 12-2", "Starlink Group 12-1", "SARah-4", "Transporter-16", "Crew-13", "CRS-35"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestQueryLimitAndPage(t *testing.T) {
+	client, requests := newClient(t, testFixtures())
+
+	// The client follows the pages from the requested one.
+	launchpads, err := client.GetLaunchpads(external.NewQuery().Sort("name").Limit(2).Page(2).Body())
+	if err != nil {
+		t.Fatalf("GetLaunchpads: %v", err)
+	}
+	want := []string{"Kwajalein Atoll", "STLS", "VAFB SLC 3W", "VAFB SLC 4E"}
+	if got := names(launchpads); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("client sent %d requests, want pages 2 and 3", got)
+	}
+
+	result, err := client.CheckScheduledLaunches(external.NewQuery().Select("id").Sort("date_utc").Limit(4).Page(2).Body())
+	if err != nil {
+		t.Fatalf("CheckScheduledLaunches: %v", err)
+	}
+	wantIDs := []models.Filtered{{ID: "64f1a3b2c9e77c0a1b2c3d05"}, {ID: "64f1a3b2c9e77c0a1b2c3d06"}}
+	if !reflect.DeepEqual(result.Docs, wantIDs) {
+		t.Errorf("got %v, want %v", result.Docs, wantIDs)
+	}
+	wantPage := models.PageInfo{TotalDocs: 6, Limit: 4, Page: 2, TotalPages: 2}
+	if result.PageInfo != wantPage {
+		t.Errorf("page info = %+v, want %+v", result.PageInfo, wantPage)
+	}
+}
+
+func TestQueryWithoutPagination(t *testing.T) {
+	client, requests := newClient(t, testFixtures())
+
+	// The limit only applies to paginated queries.
+	result, err := client.CheckScheduledLaunches(external.NewQuery().Select("id").Limit(2).Pagination(false).Body())
+	if err != nil {
+		t.Fatalf("CheckScheduledLaunches: %v", err)
+	}
+	if len(result.Docs) != 6 {
+		t.Errorf("got %d launches, want every 6 launches", len(result.Docs))
+	}
+	wantPage := models.PageInfo{TotalDocs: 6, Limit: 6, Page: 1, TotalPages: 1}
+	if result.PageInfo != wantPage {
+		t.Errorf("page info = %+v, want %+v", result.PageInfo, wantPage)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("client sent %d requests, want 1", got)
+	}
+}
+
+func TestGetLaunchpad(t *testing.T) {
+	client, _ := newClient(t, testFixtures())
+
+	state, err := client.CheckLaunchpadState("5e9e4502f5090927f8566f85")
+	if err != nil {
+		t.Fatalf("CheckLaunchpadState: %v", err)
+	}
+	if state != "under construction" {
+		t.Errorf("got state %q, want under construction", state)
+	}
+
+	if _, err := client.CheckLaunchpadState("missing"); err == nil {
+		t.Error("got no error for a missing launchpad")
+	}
+}
@@ -0,0 +1,412 @@
+package fakespacex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// queryBody is the body of a query request: a mongoose filter and the mongoose-paginate options.
+type queryBody struct {
+	Query   map[string]interface{} `json:"query"`
+	Options queryOptions           `json:"options"`
+}
+
+// queryOptions holds the supported options. Populate is accepted and ignored, since the
+// fixtures do not reference each other.
+type queryOptions struct {
+	Select     json.RawMessage `json:"select"`
+	Sort       json.RawMessage `json:"sort"`
+	Limit      *int            `json:"limit"`
+	Page       *int            `json:"page"`
+	Pagination *bool           `json:"pagination"`
+	Populate   json.RawMessage `json:"populate"`
+}
+
+// defaultLimit is the page size of mongoose-paginate when the query does not set a limit.
+const defaultLimit = 10
+
+// queryPage is a page of documents in the mongoose-paginate format returned by the SpaceX API.
+type queryPage struct {
+	Docs          []map[string]interface{} `json:"docs"`
+	TotalDocs     int                      `json:"totalDocs"`
+	Offset        int                      `json:"offset"`
+	Limit         int                      `json:"limit"`
+	TotalPages    int                      `json:"totalPages"`
+	Page          int                      `json:"page"`
+	PagingCounter int                      `json:"pagingCounter"`
+	HasPrevPage   bool                     `json:"hasPrevPage"`
+	HasNextPage   bool                     `json:"hasNextPage"`
+	PrevPage      *int                     `json:"prevPage"`
+	NextPage      *int                     `json:"nextPage"`
+}
+
+// runQuery filters, sorts, paginates and projects the documents.
+func runQuery(docs []map[string]interface{}, body queryBody) (queryPage, error) {
+	matched := []map[string]interface{}{}
+	for _, doc := range docs {
+		ok, err := matches(doc, body.Query)
+		if err != nil {
+			return queryPage{}, err
+		}
+		if ok {
+			matched = append(matched, doc)
+		}
+	}
+
+	if err := sortDocs(matched, body.Options.Sort); err != nil {
+		return queryPage{}, err
+	}
+
+	page := queryPage{TotalDocs: len(matched), Page: 1, Limit: len(matched), TotalPages: 1, PagingCounter: 1}
+	if body.Options.Pagination == nil || *body.Options.Pagination {
+		page.Limit = defaultLimit
+		if body.Options.Limit != nil {
+			page.Limit = *body.Options.Limit
+		}
+		if body.Options.Page != nil {
+			page.Page = *body.Options.Page
+		}
+		if page.Limit < 1 || page.Page < 1 {
+			return queryPage{}, fmt.Errorf("limit and page must be positive")
+		}
+
+		page.Offset = (page.Page - 1) * page.Limit
+		page.PagingCounter = page.Offset + 1
+		page.TotalPages = max(int(math.Ceil(float64(len(matched))/float64(page.Limit))), 1)
+		matched = matched[min(page.Offset, len(matched)):min(page.Offset+page.Limit, len(matched))]
+		if page.Page > 1 {
+			prev := page.Page - 1
+			page.HasPrevPage, page.PrevPage = true, &prev
+		}
+		if page.Page < page.TotalPages {
+			next := page.Page + 1
+			page.HasNextPage, page.NextPage = true, &next
+		}
+	}
+
+	page.Docs = make([]map[string]interface{}, 0, len(matched))
+	for _, doc := range matched {
+		projected, err := project(doc, body.Options.Select)
+		if err != nil {
+			return queryPage{}, err
+		}
+		page.Docs = append(page.Docs, projected)
+	}
+
+	return page, nil
+}
+
+// matches reports whether the document satisfies every condition of the query. Supported are equality,
+// the $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin and $exists operators, and the $and, $or and $nor combinators.
+// Fields of embedded documents are addressed with dots.
+func matches(doc map[string]interface{}, query map[string]interface{}) (bool, error) {
+	for field, condition := range query {
+		var ok bool
+		var err error
+		switch field {
+		case "$and", "$or", "$nor":
+			ok, err = matchesCombinator(doc, field, condition)
+		default:
+			ok, err = matchesCondition(lookup(doc, field), condition)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// matchesCombinator evaluates $and, $or and $nor over their list of queries.
+func matchesCombinator(doc map[string]interface{}, combinator string, condition interface{}) (bool, error) {
+	list, ok := condition.([]interface{})
+	if !ok || len(list) == 0 {
+		return false, fmt.Errorf("%s needs a non-empty array of queries", combinator)
+	}
+
+	matchedAny := false
+	for _, item := range list {
+		query, ok := item.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs a non-empty array of queries", combinator)
+		}
+		matched, err := matches(doc, query)
+		if err != nil {
+			return false, err
+		}
+		if combinator == "$and" && !matched {
+			return false, nil
+		}
+		matchedAny = matchedAny || matched
+	}
+
+	switch combinator {
+	case "$or":
+		return matchedAny, nil
+	case "$nor":
+		return !matchedAny, nil
+	default:
+		return true, nil
+	}
+}
+
+// matchesCondition evaluates the condition of a single field against its value, nil when the field is missing.
+func matchesCondition(value, condition interface{}) (bool, error) {
+	operators, ok := condition.(map[string]interface{})
+	if !ok || !isOperatorObject(operators) {
+		return equals(value, condition), nil
+	}
+
+	for operator, operand := range operators {
+		var ok bool
+		switch operator {
+		case "$eq":
+			ok = equals(value, operand)
+		case "$ne":
+			ok = !equals(value, operand)
+		case "$in", "$nin":
+			list, isList := operand.([]interface{})
+			if !isList {
+				return false, fmt.Errorf("%s needs an array", operator)
+			}
+			for _, item := range list {
+				ok = ok || equals(value, item)
+			}
+			if operator == "$nin" {
+				ok = !ok
+			}
+		case "$gt", "$gte", "$lt", "$lte":
+			result, comparable := compare(value, operand)
+			if comparable {
+				ok = operator == "$gt" && result > 0 || operator == "$gte" && result >= 0 ||
+					operator == "$lt" && result < 0 || operator == "$lte" && result <= 0
+			}
+		case "$exists":
+			exists, isBool := operand.(bool)
+			if !isBool {
+				return false, fmt.Errorf("$exists needs a boolean")
+			}
+			ok = (value != nil) == exists
+		default:
+			return false, fmt.Errorf("unsupported query operator %s", operator)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// isOperatorObject reports whether every key of the object is an operator.
+func isOperatorObject(object map[string]interface{}) bool {
+	for key := range object {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+
+	return len(object) > 0
+}
+
+// equals compares a document value with a query value. Like MongoDB, an array matches
+// when any of its elements is equal to the query value.
+func equals(value, expected interface{}) bool {
+	if list, ok := value.([]interface{}); ok {
+		if _, expectedList := expected.([]interface{}); !expectedList {
+			for _, item := range list {
+				if equals(item, expected) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	if result, ok := compare(value, expected); ok {
+		return result == 0
+	}
+
+	return reflect.DeepEqual(value, expected)
+}
+
+// compare orders two numbers or two strings. Strings holding RFC 3339 dates are compared as dates,
+// so "2024-12-01T00:00:00Z" and "2024-12-01T00:00:00.000Z" are equal.
+func compare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmpOrdered(a, b), true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			dateA, errA := time.Parse(time.RFC3339, a)
+			dateB, errB := time.Parse(time.RFC3339, b)
+			if errA == nil && errB == nil {
+				return dateA.Compare(dateB), true
+			}
+			return strings.Compare(a, b), true
+		}
+	}
+
+	return 0, false
+}
+
+func cmpOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// lookup returns the value of a dotted field path, or nil when it is missing.
+func lookup(doc map[string]interface{}, field string) interface{} {
+	var value interface{} = doc
+	for _, part := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+
+	return value
+}
+
+// sortField is a field of the sort option with its direction, 1 or -1.
+type sortField struct {
+	field     string
+	direction int
+}
+
+// sortDocs sorts the documents by the sort option, either an object such as {"date_utc": -1, "name": "asc"}
+// or a string such as "-date_utc name". Documents keep their fixture order on ties.
+func sortDocs(docs []map[string]interface{}, raw json.RawMessage) error {
+	fields, err := parseSort(raw)
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, field := range fields {
+			result, _ := compare(lookup(docs[i], field.field), lookup(docs[j], field.field))
+			if result != 0 {
+				return result*field.direction < 0
+			}
+		}
+		return false
+	})
+
+	return nil
+}
+
+// parseSort parses the sort option keeping the order of its fields.
+func parseSort(raw json.RawMessage) ([]sortField, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		var fields []sortField
+		for _, field := range strings.Fields(text) {
+			if strings.HasPrefix(field, "-") {
+				fields = append(fields, sortField{field: field[1:], direction: -1})
+			} else {
+				fields = append(fields, sortField{field: field, direction: 1})
+			}
+		}
+		return fields, nil
+	}
+
+	keys, values, err := orderedObject(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sort option: %w", err)
+	}
+	fields := make([]sortField, 0, len(keys))
+	for i, key := range keys {
+		switch values[i] {
+		case float64(1), "asc", "ascending":
+			fields = append(fields, sortField{field: key, direction: 1})
+		case float64(-1), "desc", "descending":
+			fields = append(fields, sortField{field: key, direction: -1})
+		default:
+			return nil, fmt.Errorf("invalid sort direction of %s: %v", key, values[i])
+		}
+	}
+
+	return fields, nil
+}
+
+// orderedObject decodes a flat JSON object into its keys and values in document order.
+func orderedObject(raw json.RawMessage) ([]string, []interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, fmt.Errorf("expected an object")
+	}
+
+	var keys []string
+	var values []interface{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, token.(string))
+		values = append(values, value)
+	}
+
+	return keys, values, nil
+}
+
+// project applies the select option, either an object such as {"id": 1, "name": 1} or a string such as "id name".
+// An inclusion keeps the id, like mongoose keeps _id; an exclusion such as {"details": 0} removes the fields.
+func project(doc map[string]interface{}, raw json.RawMessage) (map[string]interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return doc, nil
+	}
+
+	selection := map[string]bool{}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		for _, field := range strings.Fields(text) {
+			selection[strings.TrimPrefix(field, "-")] = !strings.HasPrefix(field, "-")
+		}
+	} else {
+		var object map[string]interface{}
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return nil, fmt.Errorf("invalid select option: %w", err)
+		}
+		for field, value := range object {
+			selection[field] = value == float64(1) || value == true
+		}
+	}
+
+	inclusion := false
+	for _, include := range selection {
+		inclusion = inclusion || include
+	}
+
+	projected := make(map[string]interface{}, len(doc))
+	for field, value := range doc {
+		include, selected := selection[field]
+		if inclusion && (include || field == "id" && !selected) || !inclusion && !selected {
+			projected[field] = value
+		}
+	}
+
+	return projected, nil
+}
@@ -1,7 +1,12 @@
 package service
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/klemis/go-spaceflight-booking-api/internal/database"
@@ -12,109 +17,426 @@ import (
 
 // BookingService provides methods for booking operations.
 type BookingService interface {
-	GetBookings() ([]models.Booking, error)
+	GetBookings(filter models.BookingFilter) (models.BookingPage, error)
+	GetBooking(id int) (models.Booking, error)
 	CreateBooking(request models.BookingRequest) (models.Booking, error)
-	DeleteBooking(id int) error
+	UpdateBooking(id int, request models.BookingRequest) (models.Booking, error)
+	ConfirmBooking(id int) (models.Booking, error)
+	CancelBooking(id int, reason string) (models.Booking, error)
+	MarkFlown(id int) (models.Booking, error)
+	GetSeatAvailability(destinationID models.Destination, launchDate time.Time) (models.SeatAvailability, error)
+	GetAvailability(destinationID models.Destination, from, to time.Time) ([]models.AvailableFlight, error)
 }
 
+// DegradePolicy decides how bookings are checked against SpaceX launches while the launch provider is unavailable.
+type DegradePolicy string
+
+const (
+	// DegradeReject fails bookings and availability searches with external.ErrUnavailable.
+	DegradeReject DegradePolicy = "reject"
+	// DegradeAllow assumes SpaceX does not launch from the launchpad, so bookings go through unchecked.
+	DegradeAllow DegradePolicy = "allow"
+)
+
 // bookingService is an implementation of BookingService.
 type bookingService struct {
-	externalClient *external.SpaceXAPIClient
-	db             database.DBInterface
+	providers     *external.Registry
+	degradePolicy DegradePolicy
+	db            database.DBInterface
 }
 
-// NewBookingService creates a new instance of bookingService with the external client.
-func NewBookingService(externalClient *external.SpaceXAPIClient, db database.DBInterface) BookingService {
+// NewBookingService creates a new instance of bookingService with the launch providers of the launch operators,
+// such as the SpaceX API client or an external.StaticLaunchProvider, and the policy applied while one is unavailable.
+func NewBookingService(providers *external.Registry, degradePolicy DegradePolicy, db database.DBInterface) BookingService {
 	return &bookingService{
-		externalClient: externalClient,
-		db:             db,
+		providers:     providers,
+		degradePolicy: degradePolicy,
+		db:            db,
 	}
 }
 
-// TODO:
-// - create readme
-// - describe destination_id mapping in docs
-// - add unit tests
-
 // CreateBooking creates a new booking.
 func (s *bookingService) CreateBooking(request models.BookingRequest) (models.Booking, error) {
 	// This function is designed based on the assumption that the destination is more crucial for the user than the launchpad.
 	// I created a separate binary for generating schedules (`GenerateSchedules`), that creates schedule only for active launchpads.
-	// To simplify, I removed the `LaunchpadID` parameter from the request. Instead, the function retrieves the relevant launchpad
-	// from the current schedules. It selects the appropriate launchpad based on the `DestinationID` and `LaunchDate`.
-	// FIXME: Also consider if it should be a "cancelled" flight.
-	launchpadID, err := s.db.GetLaunchpadID(request.DestinationID, request.LaunchDate)
+	// The `LaunchpadID` of the request is optional. Without it, the function retrieves the departures to the `DestinationID`
+	// on the `LaunchDate` from the current schedules and books the best one, see findDepartures.
+	// New bookings start as pending and move through the states defined in booking_state.go.
+	departures, err := s.findDepartures(request)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	// Insert booking to bookings table, it takes a seat on the flight of the first departure that still has one.
+	return bookFirst(departures, func(departure models.Departure) (models.Booking, error) {
+		return s.db.InsertBooking(request, departure)
+	})
+}
+
+// UpdateBooking replaces the passenger and flight details of an existing booking.
+// The departure is only looked up and checked against the operator's launches again when the destination, the launch date,
+// the requested launch time, the requested launchpad or the requested provider changes, otherwise the booking keeps its flight.
+func (s *bookingService) UpdateBooking(id int, request models.BookingRequest) (models.Booking, error) {
+	existing, err := s.db.GetBooking(id)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if isClosed(existing.Status) {
+		return models.Booking{}, ErrBookingClosed
+	}
+
+	update := func(departure models.Departure) (models.Booking, error) {
+		return s.db.UpdateBooking(id, request, departure)
+	}
+
+	if request.DestinationID == existing.DestinationID && request.LaunchDate.Equal(existing.LaunchDate) &&
+		(request.LaunchTime == "" || request.LaunchTime == existing.LaunchTime) &&
+		(request.LaunchpadID == "" || request.LaunchpadID == existing.LaunchpadID) &&
+		(request.Provider == "" || request.Provider == existing.Provider) {
+		return update(models.Departure{
+			LaunchpadID:   existing.LaunchpadID,
+			Provider:      existing.Provider,
+			DestinationID: existing.DestinationID,
+			LaunchDate:    existing.LaunchDate,
+			LaunchTime:    existing.LaunchTime,
+		})
+	}
+
+	departures, err := s.findDepartures(request)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	return bookFirst(departures, update)
+}
+
+// ConfirmBooking moves a pending booking to confirmed.
+func (s *bookingService) ConfirmBooking(id int) (models.Booking, error) {
+	return s.transition(id, models.BookingConfirmed, "")
+}
+
+// CancelBooking cancels a pending or confirmed booking. The booking is kept for history.
+func (s *bookingService) CancelBooking(id int, reason string) (models.Booking, error) {
+	return s.transition(id, models.BookingCancelled, reason)
+}
+
+// MarkFlown marks a confirmed booking as flown.
+func (s *bookingService) MarkFlown(id int) (models.Booking, error) {
+	return s.transition(id, models.BookingFlown, "")
+}
+
+// transition moves a booking to the given status if the state machine allows it.
+func (s *bookingService) transition(id int, to models.BookingStatus, reason string) (models.Booking, error) {
+	booking, err := s.db.GetBooking(id)
 	if err != nil {
 		return models.Booking{}, err
 	}
 
-	body := prepareRequestBody(launchpadID, request.LaunchDate)
-	launches, err := s.externalClient.CheckScheduledLaunches(body)
+	if !canTransition(booking.Status, to) {
+		return models.Booking{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, booking.Status, to)
+	}
+
+	err = s.db.UpdateBookingStatus(id, booking.Status, to, reason)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The booking was changed concurrently and is no longer in the status it was read in.
+			return models.Booking{}, fmt.Errorf("%w: booking status changed concurrently", ErrInvalidTransition)
+		}
+
 		return models.Booking{}, err
 	}
-	if len(launches.Docs) != 0 {
-		// FIXME: Can we assume that this is a "cancelled" flight? Currently its rather not created at all.
-		// - Extend Booking struct by State and set state = "cancelled" here.
-		return models.Booking{}, fmt.Errorf("launchpad has already been reserved")
+
+	booking.Status = to
+	booking.CancelReason = reason
+
+	return booking, nil
+}
+
+// defaultPageLimit is the number of bookings returned when the limit is not specified.
+const defaultPageLimit = 20
+
+func (s *bookingService) GetBookings(filter models.BookingFilter) (models.BookingPage, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+
+	page, err := s.db.GetBookings(filter)
+	if err != nil {
+		return models.BookingPage{}, err
 	}
 
-	// Insert booking to bookings table.
-	id, err := s.db.InsertBooking(request, launchpadID)
+	return page, nil
+}
+
+func (s *bookingService) GetBooking(id int) (models.Booking, error) {
+	booking, err := s.db.GetBooking(id)
 	if err != nil {
 		return models.Booking{}, err
 	}
 
-	return models.Booking{
-		ID:            id,
-		FirstName:     request.FirstName,
-		LastName:      request.LastName,
-		Gender:        request.Gender,
-		Birthday:      request.Birthday,
-		LaunchpadID:   launchpadID,
-		DestinationID: request.DestinationID,
-		LaunchDate:    request.LaunchDate,
+	return booking, nil
+}
+
+// GetSeatAvailability returns the remaining seats of the departure to the destination on the launch date
+// that a new booking would be placed on, the one with the most free seats.
+func (s *bookingService) GetSeatAvailability(destinationID models.Destination, launchDate time.Time) (models.SeatAvailability, error) {
+	departures, err := s.db.GetDepartures(destinationID, launchDate)
+	if err != nil {
+		return models.SeatAvailability{}, err
+	}
+	if len(departures) == 0 {
+		return models.SeatAvailability{}, ErrNoDeparture
+	}
+	rankDepartures(departures)
+
+	best := departures[0]
+	return models.SeatAvailability{
+		LaunchpadID:   best.LaunchpadID,
+		Provider:      best.Provider,
+		DestinationID: destinationID,
+		LaunchDate:    launchDate,
+		LaunchTime:    best.LaunchTime,
+		Capacity:      best.Capacity,
+		Booked:        best.Booked,
+		Remaining:     best.Remaining,
 	}, nil
 }
 
-func (s *bookingService) DeleteBooking(id int) error {
-	err := s.db.DeleteBooking(id)
+// maxAvailabilityDays limits the number of days searched by GetAvailability,
+// since every scheduled day is checked against SpaceX launches.
+const maxAvailabilityDays = 31
+
+// GetAvailability returns the bookable departures to the destination between from and to, both inclusive.
+// It walks the schedules day by day, including their overrides, and skips departures from launchpads
+// where their operator launches that day and fully booked flights.
+func (s *bookingService) GetAvailability(destinationID models.Destination, from, to time.Time) ([]models.AvailableFlight, error) {
+	if to.Before(from) || to.Sub(from) >= maxAvailabilityDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: to must not be before from and the range is limited to %d days", ErrInvalidDateRange, maxAvailabilityDays)
+	}
+
+	available := []models.AvailableFlight{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		departures, err := s.db.GetDepartures(destinationID, day)
+		if err != nil {
+			return nil, err
+		}
+
+		reserved := map[string]bool{}
+		for _, departure := range departures {
+			if departure.Remaining == 0 {
+				continue
+			}
+			key := departure.Provider + "/" + departure.LaunchpadID
+			isReserved, ok := reserved[key]
+			if !ok {
+				isReserved, err = s.isLaunchpadReserved(departure.Provider, departure.LaunchpadID, day)
+				if err != nil {
+					return nil, err
+				}
+				reserved[key] = isReserved
+			}
+			if isReserved {
+				continue
+			}
+
+			available = append(available, models.AvailableFlight{
+				LaunchDate:    day,
+				LaunchTime:    departure.LaunchTime,
+				LaunchpadID:   departure.LaunchpadID,
+				Provider:      departure.Provider,
+				DestinationID: destinationID,
+				Remaining:     departure.Remaining,
+			})
+		}
+	}
+
+	return available, nil
+}
+
+// findDepartures returns the departures the booking can be placed on, best first, see rankDepartures.
+// A requested launch time, launchpad or provider only keeps the departures at that time, from that launchpad
+// or of that provider, and departures from launchpads where their operator has a launch planned that day are left out.
+func (s *bookingService) findDepartures(request models.BookingRequest) ([]models.Departure, error) {
+	launchTime := request.LaunchTime
+	if launchTime != "" {
+		var err error
+		if launchTime, err = normalizeLaunchTime(launchTime); err != nil {
+			return nil, err
+		}
+	}
+	if request.Provider != "" {
+		if _, err := s.providers.Get(request.Provider); err != nil {
+			return nil, err
+		}
+	}
+
+	departures, err := s.db.GetDepartures(request.DestinationID, request.LaunchDate)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var candidates []models.Departure
+	reserved := map[string]bool{}
+	for _, departure := range departures {
+		if launchTime != "" && departure.LaunchTime != launchTime {
+			continue
+		}
+		if request.LaunchpadID != "" && departure.LaunchpadID != request.LaunchpadID {
+			continue
+		}
+		if request.Provider != "" && departure.Provider != request.Provider {
+			continue
+		}
+		key := departure.Provider + "/" + departure.LaunchpadID
+		isReserved, ok := reserved[key]
+		if !ok {
+			isReserved, err = s.isLaunchpadReserved(departure.Provider, departure.LaunchpadID, request.LaunchDate)
+			if err != nil {
+				return nil, err
+			}
+			reserved[key] = isReserved
+		}
+		if !isReserved {
+			candidates = append(candidates, departure)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if len(reserved) == 0 && request.LaunchpadID != "" {
+			return nil, s.explainLaunchpad(request, launchTime)
+		}
+		if len(reserved) > 0 {
+			// Every matching departure leaves from a launchpad its operator launches from that day.
+			// The booking is not created at all, so there is nothing to cancel here.
+			return nil, ErrLaunchpadReserved
+		}
+
+		return nil, ErrNoDeparture
 	}
+	rankDepartures(candidates)
 
-	return nil
+	return candidates, nil
 }
 
-func (s *bookingService) GetBookings() ([]models.Booking, error) {
-	bookings, err := s.db.GetBookings()
+// explainLaunchpad returns why the launchpad chosen for the booking has no departure matching the request,
+// looking at every departure of the launchpad on the launch date. The launch time is the normalized requested one, if any.
+func (s *bookingService) explainLaunchpad(request models.BookingRequest, launchTime string) error {
+	departures, err := s.db.GetLaunchpadDepartures(request.LaunchpadID, request.LaunchDate)
 	if err != nil {
-		return []models.Booking{}, err
+		return err
+	}
+	if len(departures) == 0 {
+		return fmt.Errorf("%w: launchpad %s has no departures on this date", ErrLaunchpadNotScheduled, request.LaunchpadID)
+	}
+	if request.Provider != "" && departures[0].Provider != request.Provider {
+		return fmt.Errorf("%w: launchpad %s is a launchpad of %s", ErrLaunchpadNotScheduled, request.LaunchpadID, departures[0].Provider)
 	}
 
-	return bookings, nil
+	var destinations []string
+	seen := map[models.Destination]bool{}
+	toDestination, atLaunchTime := false, false
+	for _, departure := range departures {
+		if departure.DestinationID == request.DestinationID {
+			toDestination = true
+			atLaunchTime = atLaunchTime || departure.LaunchTime == launchTime
+		}
+		if !seen[departure.DestinationID] {
+			seen[departure.DestinationID] = true
+			destinations = append(destinations, utils.String(departure.DestinationID))
+		}
+	}
+	if !toDestination {
+		return fmt.Errorf("%w: launchpad %s flies to %s on this date", ErrLaunchpadNotScheduled, request.LaunchpadID, strings.Join(destinations, ", "))
+	}
+	if launchTime != "" && !atLaunchTime {
+		return fmt.Errorf("%w: launchpad %s has no departure to %s at %s on this date", ErrLaunchpadNotScheduled,
+			request.LaunchpadID, utils.String(request.DestinationID), launchTime)
+	}
+
+	// The launchpad flies to the destination, at the requested time if any, but the flight was cancelled.
+	return database.ErrFlightCancelled
 }
 
-// prepareRequestBody constructs a RequestBody with extended options.
-func prepareRequestBody(launchpadId string, launchDate time.Time) models.RequestBody {
-	gte, lt := utils.GetRangeQueryValues(launchDate)
+// rankDepartures orders the departures by free seats, most first, which spreads the passengers over the
+// launchpads flying to the same destination. Ties keep the earlier departure first.
+func rankDepartures(departures []models.Departure) {
+	sort.SliceStable(departures, func(i, j int) bool {
+		return departures[i].Remaining > departures[j].Remaining
+	})
+}
 
-	options := models.Options{
-		Select: map[string]int{
-			"id": 1,
-		},
+// bookFirst books the departures in order until one has a free seat. A departure can fill up, get
+// cancelled or rerouted between the lookup and the reservation, in which case the next one is tried.
+func bookFirst(departures []models.Departure, book func(departure models.Departure) (models.Booking, error)) (models.Booking, error) {
+	var err error
+	for _, departure := range departures {
+		var booking models.Booking
+		booking, err = book(departure)
+		if err == nil {
+			return booking, nil
+		}
+		if !errors.Is(err, database.ErrFlightFull) && !errors.Is(err, database.ErrFlightCancelled) &&
+			!errors.Is(err, database.ErrFlightRerouted) {
+			return models.Booking{}, err
+		}
+	}
+
+	return models.Booking{}, err
+}
+
+// launchSyncMaxAge is how old the launches synchronized by the sync binary may be to be used instead of the launch provider.
+const launchSyncMaxAge = 2 * time.Hour
+
+// isLaunchpadReserved reports whether the operator of the launchpad, registered as provider, has a launch planned
+// from it on the launch day. For SpaceX, recently synchronized launches are read from the database, otherwise the
+// launch provider is asked. While the launch provider is unavailable, or has too many launches to fetch them all,
+// older synchronized SpaceX launches are used, and without any the DegradeAllow policy reports the launchpad as free.
+func (s *bookingService) isLaunchpadReserved(provider, launchpadID string, launchDate time.Time) (bool, error) {
+	launchProvider, err := s.providers.Get(provider)
+	if err != nil {
+		// The departure was scheduled for a provider that is no longer registered, which is a configuration error.
+		return false, fmt.Errorf("failed to check launchpad %s: %v", launchpadID, err)
 	}
 
-	body := models.RequestBody{
-		Query: map[string]interface{}{
-			"launchpad": launchpadId,
-			"date_utc": map[string]string{
-				"$gte": gte,
-				"$lt":  lt,
-			},
-		},
-		Options: options,
+	// Only the SpaceX launches are synchronized by the sync binary.
+	var syncedAt time.Time
+	if provider == external.SpaceXProvider {
+		syncedAt, err = s.db.GetLastSync(database.LaunchesResource)
+		if err != nil {
+			return false, err
+		}
 	}
+	if !syncedAt.IsZero() && time.Since(syncedAt) <= launchSyncMaxAge {
+		return s.db.HasLaunch(launchpadID, launchDate)
+	}
+
+	launches, err := launchProvider.CheckScheduledLaunches(prepareRequestBody(launchpadID, launchDate))
+	if errors.Is(err, external.ErrTooManyPages) {
+		// The launches could not all be fetched, which leaves the launchpad as unknown as an unavailable provider.
+		err = fmt.Errorf("%w: %w", external.ErrUnavailable, err)
+	}
+	if err != nil {
+		if errors.Is(err, external.ErrUnavailable) && !syncedAt.IsZero() {
+			log.Printf("launch provider unavailable, using launches synchronized at %s for launchpad %s: %v", syncedAt.Format(time.RFC3339), launchpadID, err)
+			return s.db.HasLaunch(launchpadID, launchDate)
+		}
+		if errors.Is(err, external.ErrUnavailable) && s.degradePolicy == DegradeAllow {
+			log.Printf("launch provider unavailable, assuming launchpad %s is free on %s: %v", launchpadID, launchDate.Format("2006-01-02"), err)
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return len(launches.Docs) != 0, nil
+}
+
+// prepareRequestBody constructs the query of the launches from the launchpad on the day of the launch date.
+func prepareRequestBody(launchpadId string, launchDate time.Time) models.RequestBody {
+	gte, lt := utils.GetRangeQueryValues(launchDate)
 
-	return body
+	return external.NewQuery(
+		external.Eq("launchpad", launchpadId),
+		external.Range("date_utc", gte, lt),
+	).Select("id").Body()
 }
@@ -0,0 +1,456 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+var launchDate = time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+// stubDB is a database.DBInterface holding the departures of launchDate. Only the methods used
+// when creating bookings are implemented, the others panic through the nil embedded interface.
+type stubDB struct {
+	database.DBInterface
+	departures []models.Departure
+	// cancelled launchpads only appear in GetLaunchpadDepartures, like cancelled flights.
+	cancelled map[string]bool
+	// full launchpads fail InsertBooking with database.ErrFlightFull.
+	full map[string]bool
+	// syncedAt and launches are the SpaceX launches synchronized by the sync binary.
+	syncedAt time.Time
+	launches map[string]bool
+	// attempts lists the launchpads InsertBooking was called with.
+	attempts []string
+}
+
+func (db *stubDB) GetDepartures(destinationID models.Destination, _ time.Time) ([]models.Departure, error) {
+	var departures []models.Departure
+	for _, departure := range db.departures {
+		if departure.DestinationID == destinationID && !db.cancelled[departure.LaunchpadID] {
+			departures = append(departures, departure)
+		}
+	}
+
+	return departures, nil
+}
+
+func (db *stubDB) GetLaunchpadDepartures(launchpadID string, _ time.Time) ([]models.Departure, error) {
+	var departures []models.Departure
+	for _, departure := range db.departures {
+		if departure.LaunchpadID == launchpadID {
+			departures = append(departures, departure)
+		}
+	}
+
+	return departures, nil
+}
+
+func (db *stubDB) InsertBooking(request models.BookingRequest, departure models.Departure) (models.Booking, error) {
+	db.attempts = append(db.attempts, departure.LaunchpadID)
+	if db.full[departure.LaunchpadID] {
+		return models.Booking{}, database.ErrFlightFull
+	}
+
+	return models.Booking{
+		ID:            uint(len(db.attempts)),
+		FirstName:     request.FirstName,
+		LaunchpadID:   departure.LaunchpadID,
+		Provider:      departure.Provider,
+		DestinationID: departure.DestinationID,
+		LaunchDate:    departure.LaunchDate,
+		LaunchTime:    departure.LaunchTime,
+		Status:        models.BookingPending,
+	}, nil
+}
+
+func (db *stubDB) GetLastSync(string) (time.Time, error) {
+	return db.syncedAt, nil
+}
+
+func (db *stubDB) HasLaunch(launchpadID string, _ time.Time) (bool, error) {
+	return db.launches[launchpadID], nil
+}
+
+func departure(launchpadID, provider string, destinationID models.Destination, launchTime string, remaining int) models.Departure {
+	return models.Departure{
+		LaunchpadID:   launchpadID,
+		Provider:      provider,
+		DestinationID: destinationID,
+		LaunchDate:    launchDate,
+		LaunchTime:    launchTime,
+		Capacity:      10,
+		Booked:        10 - remaining,
+		Remaining:     remaining,
+	}
+}
+
+// newStubDB returns a day with three SpaceX launchpads flying to Mars, the most free seats first,
+// pad-d flying to the Moon and Pluto, and the acme launchpad of the acme provider flying to Mars.
+func newStubDB() *stubDB {
+	return &stubDB{
+		departures: []models.Departure{
+			departure("pad-a", external.SpaceXProvider, models.Mars, "12:00", 8),
+			departure("pad-b", external.SpaceXProvider, models.Mars, "12:00", 5),
+			departure("pad-c", external.SpaceXProvider, models.Mars, "18:00", 2),
+			departure("pad-d", external.SpaceXProvider, models.Moon, "12:00", 10),
+			departure("pad-d", external.SpaceXProvider, models.Pluto, "18:00", 10),
+			departure("acme-1", "acme", models.Mars, "09:00", 4),
+		},
+		cancelled: map[string]bool{},
+		full:      map[string]bool{},
+		launches:  map[string]bool{},
+	}
+}
+
+// newTestService returns a booking service with fake SpaceX and acme launch providers.
+func newTestService(t *testing.T, db *stubDB, policy DegradePolicy) (BookingService, *external.FakeLaunchProvider) {
+	t.Helper()
+
+	spacex := external.NewFakeLaunchProvider()
+	providers := external.NewRegistry(external.SpaceXProvider)
+	if err := providers.Register(external.SpaceXProvider, spacex); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+	if err := providers.Register("acme", external.NewFakeLaunchProvider()); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+
+	return NewBookingService(providers, policy, db), spacex
+}
+
+func bookingRequest(destinationID models.Destination) models.BookingRequest {
+	return models.BookingRequest{
+		FirstName:     "Ada",
+		LastName:      "Lovelace",
+		Gender:        "female",
+		Birthday:      time.Date(1990, 12, 10, 0, 0, 0, 0, time.UTC),
+		DestinationID: destinationID,
+		LaunchDate:    launchDate,
+	}
+}
+
+// launchFrom returns a launch from the launchpad in the afternoon of launchDate.
+func launchFrom(launchpadID string) external.FakeLaunch {
+	return external.FakeLaunch{ID: "launch-" + launchpadID, LaunchpadID: launchpadID, DateUTC: launchDate.Add(15 * time.Hour)}
+}
+
+func TestCreateBookingRanksDepartures(t *testing.T) {
+	db := newStubDB()
+	bookings, _ := newTestService(t, db, DegradeReject)
+
+	booking, err := bookings.CreateBooking(bookingRequest(models.Mars))
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.LaunchpadID != "pad-a" || booking.Status != models.BookingPending {
+		t.Errorf("booked %s as %s, want a pending booking on pad-a with the most free seats", booking.LaunchpadID, booking.Status)
+	}
+}
+
+func TestCreateBookingSkipsReservedLaunchpads(t *testing.T) {
+	db := newStubDB()
+	bookings, spacex := newTestService(t, db, DegradeReject)
+	spacex.AddLaunch(launchFrom("pad-a"))
+
+	booking, err := bookings.CreateBooking(bookingRequest(models.Mars))
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.LaunchpadID != "pad-b" {
+		t.Errorf("booked %s, want pad-b since SpaceX launches from pad-a", booking.LaunchpadID)
+	}
+
+	// A launch on another day does not reserve the launchpad.
+	spacex.AddLaunch(external.FakeLaunch{ID: "launch-next-day", LaunchpadID: "pad-b", DateUTC: launchDate.AddDate(0, 0, 1)})
+	booking, err = bookings.CreateBooking(bookingRequest(models.Mars))
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.LaunchpadID != "pad-b" {
+		t.Errorf("booked %s, want pad-b", booking.LaunchpadID)
+	}
+}
+
+func TestCreateBookingOnReservedLaunchpad(t *testing.T) {
+	db := newStubDB()
+	bookings, spacex := newTestService(t, db, DegradeReject)
+	spacex.AddLaunch(launchFrom("pad-a"))
+
+	request := bookingRequest(models.Mars)
+	request.LaunchpadID = "pad-a"
+	if _, err := bookings.CreateBooking(request); !errors.Is(err, ErrLaunchpadReserved) {
+		t.Fatalf("got error %v, want ErrLaunchpadReserved", err)
+	}
+	if len(db.attempts) != 0 {
+		t.Errorf("booking was attempted on %v", db.attempts)
+	}
+}
+
+func TestCreateBookingUsesSynchronizedLaunches(t *testing.T) {
+	db := newStubDB()
+	db.syncedAt = time.Now().Add(-time.Hour)
+	db.launches["pad-a"] = true
+	bookings, spacex := newTestService(t, db, DegradeReject)
+	// Recently synchronized launches are used without asking SpaceX.
+	spacex.SetError(external.ErrUnavailable)
+
+	booking, err := bookings.CreateBooking(bookingRequest(models.Mars))
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.LaunchpadID != "pad-b" {
+		t.Errorf("booked %s, want pad-b since the synchronized launches reserve pad-a", booking.LaunchpadID)
+	}
+}
+
+func TestCreateBookingWhileProviderFails(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name          string
+		err           error
+		policy        DegradePolicy
+		syncedAt      time.Time
+		wantErr       error
+		wantLaunchpad string
+	}{
+		{name: "reject", err: external.ErrUnavailable, policy: DegradeReject, wantErr: external.ErrUnavailable},
+		{name: "allow", err: external.ErrUnavailable, policy: DegradeAllow, wantLaunchpad: "pad-a"},
+		{name: "circuit open", err: external.ErrCircuitOpen, policy: DegradeReject, wantErr: external.ErrUnavailable},
+		{
+			name:    "too many pages reject",
+			err:     fmt.Errorf("%w: 120 documents on 12 pages", external.ErrTooManyPages),
+			policy:  DegradeReject,
+			wantErr: external.ErrUnavailable,
+		},
+		{
+			name:          "too many pages allow",
+			err:           fmt.Errorf("%w: 120 documents on 12 pages", external.ErrTooManyPages),
+			policy:        DegradeAllow,
+			wantLaunchpad: "pad-a",
+		},
+		{
+			// Stale synchronized launches are preferred over the policy, they reserve pad-a.
+			name:          "stale sync",
+			err:           external.ErrUnavailable,
+			policy:        DegradeReject,
+			syncedAt:      time.Now().Add(-24 * time.Hour),
+			wantLaunchpad: "pad-b",
+		},
+		{name: "other errors", err: errBoom, policy: DegradeAllow, wantErr: errBoom},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newStubDB()
+			db.syncedAt = tc.syncedAt
+			db.launches["pad-a"] = true
+			bookings, spacex := newTestService(t, db, tc.policy)
+			spacex.SetError(tc.err)
+
+			booking, err := bookings.CreateBooking(bookingRequest(models.Mars))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+				if len(db.attempts) != 0 {
+					t.Errorf("booking was attempted on %v", db.attempts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateBooking: %v", err)
+			}
+			if booking.LaunchpadID != tc.wantLaunchpad {
+				t.Errorf("booked %s, want %s", booking.LaunchpadID, tc.wantLaunchpad)
+			}
+		})
+	}
+}
+
+func TestCreateBookingFallsBackWhenFlightIsFull(t *testing.T) {
+	db := newStubDB()
+	db.full["pad-a"] = true
+	db.full["pad-b"] = true
+	bookings, _ := newTestService(t, db, DegradeReject)
+
+	booking, err := bookings.CreateBooking(bookingRequest(models.Mars))
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.LaunchpadID != "acme-1" {
+		t.Errorf("booked %s, want acme-1, the next departure with free seats", booking.LaunchpadID)
+	}
+	if want := []string{"pad-a", "pad-b", "acme-1"}; !reflect.DeepEqual(db.attempts, want) {
+		t.Errorf("attempted %v, want %v", db.attempts, want)
+	}
+}
+
+func TestBookFirst(t *testing.T) {
+	departures := []models.Departure{
+		departure("pad-a", external.SpaceXProvider, models.Mars, "12:00", 1),
+		departure("pad-b", external.SpaceXProvider, models.Mars, "12:00", 1),
+		departure("pad-c", external.SpaceXProvider, models.Mars, "12:00", 1),
+	}
+	failOn := func(errs map[string]error) (func(models.Departure) (models.Booking, error), *[]string) {
+		var attempts []string
+		return func(departure models.Departure) (models.Booking, error) {
+			attempts = append(attempts, departure.LaunchpadID)
+			if err := errs[departure.LaunchpadID]; err != nil {
+				return models.Booking{}, err
+			}
+			return models.Booking{LaunchpadID: departure.LaunchpadID}, nil
+		}, &attempts
+	}
+
+	tests := []struct {
+		name          string
+		errs          map[string]error
+		wantLaunchpad string
+		wantErr       error
+		wantAttempts  []string
+	}{
+		{
+			name:          "full and cancelled flights are skipped",
+			errs:          map[string]error{"pad-a": database.ErrFlightFull, "pad-b": database.ErrFlightCancelled},
+			wantLaunchpad: "pad-c",
+			wantAttempts:  []string{"pad-a", "pad-b", "pad-c"},
+		},
+		{
+			name:          "rerouted flights are skipped",
+			errs:          map[string]error{"pad-a": database.ErrFlightRerouted},
+			wantLaunchpad: "pad-b",
+			wantAttempts:  []string{"pad-a", "pad-b"},
+		},
+		{
+			name:         "other errors stop",
+			errs:         map[string]error{"pad-a": database.ErrFlightFull, "pad-b": external.ErrUnavailable},
+			wantErr:      external.ErrUnavailable,
+			wantAttempts: []string{"pad-a", "pad-b"},
+		},
+		{
+			name:         "every flight is full",
+			errs:         map[string]error{"pad-a": database.ErrFlightFull, "pad-b": database.ErrFlightFull, "pad-c": database.ErrFlightFull},
+			wantErr:      database.ErrFlightFull,
+			wantAttempts: []string{"pad-a", "pad-b", "pad-c"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			book, attempts := failOn(tc.errs)
+			booking, err := bookFirst(departures, book)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if booking.LaunchpadID != tc.wantLaunchpad {
+				t.Errorf("booked %q, want %q", booking.LaunchpadID, tc.wantLaunchpad)
+			}
+			if !reflect.DeepEqual(*attempts, tc.wantAttempts) {
+				t.Errorf("attempted %v, want %v", *attempts, tc.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRankDepartures(t *testing.T) {
+	departures := []models.Departure{
+		departure("pad-a", external.SpaceXProvider, models.Mars, "09:00", 2),
+		departure("pad-b", external.SpaceXProvider, models.Mars, "12:00", 7),
+		departure("pad-c", external.SpaceXProvider, models.Mars, "15:00", 0),
+		departure("pad-d", external.SpaceXProvider, models.Mars, "18:00", 7),
+		departure("pad-e", external.SpaceXProvider, models.Mars, "21:00", 3),
+	}
+	rankDepartures(departures)
+
+	var got []string
+	for _, departure := range departures {
+		got = append(got, departure.LaunchpadID)
+	}
+	// Ties keep the earlier departure first.
+	if want := []string{"pad-b", "pad-d", "pad-e", "pad-a", "pad-c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ranked %v, want %v", got, want)
+	}
+}
+
+func TestCreateBookingExplainsLaunchpad(t *testing.T) {
+	tests := []struct {
+		name        string
+		launchpadID string
+		provider    string
+		destination models.Destination
+		launchTime  string
+		wantErr     error
+		wantMessage string
+	}{
+		{
+			name:        "no departures",
+			launchpadID: "pad-z",
+			destination: models.Mars,
+			wantErr:     ErrLaunchpadNotScheduled,
+			wantMessage: ErrLaunchpadNotScheduled.Error() + ": launchpad pad-z has no departures on this date",
+		},
+		{
+			name:        "other provider",
+			launchpadID: "acme-1",
+			provider:    external.SpaceXProvider,
+			destination: models.Mars,
+			wantErr:     ErrLaunchpadNotScheduled,
+			wantMessage: ErrLaunchpadNotScheduled.Error() + ": launchpad acme-1 is a launchpad of acme",
+		},
+		{
+			name:        "other destinations",
+			launchpadID: "pad-d",
+			destination: models.Mars,
+			wantErr:     ErrLaunchpadNotScheduled,
+			wantMessage: ErrLaunchpadNotScheduled.Error() + ": launchpad pad-d flies to Moon, Pluto on this date",
+		},
+		{
+			name:        "other launch time",
+			launchpadID: "pad-c",
+			destination: models.Mars,
+			launchTime:  "9:00",
+			wantErr:     ErrLaunchpadNotScheduled,
+			wantMessage: ErrLaunchpadNotScheduled.Error() + ": launchpad pad-c has no departure to Mars at 09:00 on this date",
+		},
+		{
+			name:        "cancelled flight",
+			launchpadID: "pad-b",
+			destination: models.Mars,
+			wantErr:     database.ErrFlightCancelled,
+			wantMessage: database.ErrFlightCancelled.Error(),
+		},
+		{
+			name:        "no departure to the destination",
+			destination: models.Titan,
+			wantErr:     ErrNoDeparture,
+			wantMessage: ErrNoDeparture.Error(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newStubDB()
+			db.cancelled["pad-b"] = true
+			bookings, _ := newTestService(t, db, DegradeReject)
+
+			request := bookingRequest(tc.destination)
+			request.LaunchpadID = tc.launchpadID
+			request.Provider = tc.provider
+			request.LaunchTime = tc.launchTime
+			_, err := bookings.CreateBooking(request)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if err.Error() != tc.wantMessage {
+				t.Errorf("got message %q, want %q", err.Error(), tc.wantMessage)
+			}
+		})
+	}
+}
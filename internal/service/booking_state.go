@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+var (
+	// ErrInvalidTransition is returned when a booking cannot move to the requested status.
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+	// ErrBookingClosed is returned when a cancelled or flown booking is modified.
+	ErrBookingClosed = errors.New("booking is cancelled or has already flown")
+	// ErrLaunchpadReserved is returned when SpaceX has a launch planned from the launchpad on the launch day.
+	ErrLaunchpadReserved = errors.New("launchpad has already been reserved")
+	// ErrLaunchpadNotScheduled is returned when the launchpad chosen for a booking does not fly to the destination on the launch date.
+	ErrLaunchpadNotScheduled = errors.New("launchpad is not scheduled to fly to the destination at this date")
+	// ErrNoDeparture is returned when nothing flies to the destination on the launch date.
+	ErrNoDeparture = errors.New("missing launchpad for the provided destination at this date")
+	// ErrInvalidDateRange is returned when an availability search has an invalid or too long date range.
+	ErrInvalidDateRange = errors.New("invalid date range")
+)
+
+// transitions lists the statuses a booking can move to from each status.
+// Cancelled and flown bookings are final.
+var transitions = map[models.BookingStatus][]models.BookingStatus{
+	models.BookingPending:   {models.BookingConfirmed, models.BookingCancelled},
+	models.BookingConfirmed: {models.BookingCancelled, models.BookingFlown},
+}
+
+// canTransition reports whether a booking can move from one status to another.
+func canTransition(from, to models.BookingStatus) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isClosed reports whether a booking in the given status can no longer be changed.
+func isClosed(status models.BookingStatus) bool {
+	return len(transitions[status]) == 0
+}
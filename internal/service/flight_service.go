@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// FlightService provides methods for flight operations.
+type FlightService interface {
+	GetFlights(filter models.FlightFilter) ([]models.Flight, error)
+	GetFlight(id int) (models.Flight, error)
+}
+
+// flightService is an implementation of FlightService.
+type flightService struct {
+	db database.DBInterface
+}
+
+// NewFlightService creates a new instance of flightService.
+func NewFlightService(db database.DBInterface) FlightService {
+	return &flightService{
+		db: db,
+	}
+}
+
+// defaultFlightsLimit is the number of flights returned when the limit is not specified.
+const defaultFlightsLimit = 100
+
+func (s *flightService) GetFlights(filter models.FlightFilter) ([]models.Flight, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultFlightsLimit
+	}
+
+	flights, err := s.db.GetFlights(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return flights, nil
+}
+
+func (s *flightService) GetFlight(id int) (models.Flight, error) {
+	flight, err := s.db.GetFlight(id)
+	if err != nil {
+		return models.Flight{}, err
+	}
+
+	return flight, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// LaunchCacheService provides methods for the cache of the SpaceX launch and launchpad lookups.
+type LaunchCacheService interface {
+	GetStats() models.LaunchCacheStats
+	Invalidate(launchpadID string) (int, error)
+}
+
+// launchCacheService is an implementation of LaunchCacheService.
+type launchCacheService struct {
+	cache *external.CachedLaunchProvider
+}
+
+// NewLaunchCacheService creates a new instance of launchCacheService. A nil cache means caching is disabled,
+// the stats are then empty and there is nothing to invalidate.
+func NewLaunchCacheService(cache *external.CachedLaunchProvider) LaunchCacheService {
+	return &launchCacheService{
+		cache: cache,
+	}
+}
+
+func (s *launchCacheService) GetStats() models.LaunchCacheStats {
+	if s.cache == nil {
+		return models.LaunchCacheStats{}
+	}
+
+	return s.cache.Stats()
+}
+
+// Invalidate removes the cached entries of the launchpad, or every entry when launchpadID is empty,
+// and returns the number of removed entries.
+func (s *launchCacheService) Invalidate(launchpadID string) (int, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+
+	return s.cache.Invalidate(launchpadID)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// LaunchpadService provides methods for launchpad operations.
+type LaunchpadService interface {
+	GetLaunchpads(filter models.LaunchpadFilter) ([]models.LaunchpadSummary, error)
+}
+
+// launchpadService is an implementation of LaunchpadService.
+type launchpadService struct {
+	db database.DBInterface
+}
+
+// NewLaunchpadService creates a new instance of launchpadService.
+func NewLaunchpadService(db database.DBInterface) LaunchpadService {
+	return &launchpadService{
+		db: db,
+	}
+}
+
+// GetLaunchpads returns the launchpads synchronized from SpaceX by the sync binary.
+func (s *launchpadService) GetLaunchpads(filter models.LaunchpadFilter) ([]models.LaunchpadSummary, error) {
+	launchpads, err := s.db.GetLaunchpads(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return launchpads, nil
+}
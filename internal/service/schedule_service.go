@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/internal/database"
+	"github.com/klemis/go-spaceflight-booking-api/internal/external"
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+var (
+	// ErrInvalidLaunchTime is returned when a launch time is not a valid HH:MM time.
+	ErrInvalidLaunchTime = errors.New("launch time must be a valid HH:MM time")
+	// ErrScheduleApplied is returned when a schedule version that already applies would be rewritten or deleted.
+	ErrScheduleApplied = errors.New("schedule version already applies, close it with valid_to and create a new version instead")
+	// ErrInvalidOverride is returned when a schedule override adding a departure has no destination.
+	ErrInvalidOverride = errors.New("an override adding a departure needs a destination")
+)
+
+// ScheduleService provides methods for schedule operations.
+type ScheduleService interface {
+	GetSchedules(filter models.ScheduleFilter) ([]models.Schedule, error)
+	GetSchedule(id int) (models.Schedule, error)
+	CreateSchedule(request models.ScheduleRequest) (models.Schedule, error)
+	UpdateSchedule(id int, request models.ScheduleRequest) (models.Schedule, error)
+	DeleteSchedule(id int) error
+	GetScheduleOverrides(filter models.ScheduleOverrideFilter) ([]models.ScheduleOverride, error)
+	CreateScheduleOverride(request models.ScheduleOverrideRequest) (models.ScheduleOverride, error)
+	DeleteScheduleOverride(id int) error
+}
+
+// scheduleService is an implementation of ScheduleService.
+type scheduleService struct {
+	providers *external.Registry
+	db        database.DBInterface
+}
+
+// NewScheduleService creates a new instance of scheduleService with the launch providers schedules can be created for.
+func NewScheduleService(providers *external.Registry, db database.DBInterface) ScheduleService {
+	return &scheduleService{
+		providers: providers,
+		db:        db,
+	}
+}
+
+// GetSchedules returns the schedules valid on filter.At, today by default, or every version when the filter asks for the history.
+func (s *scheduleService) GetSchedules(filter models.ScheduleFilter) ([]models.Schedule, error) {
+	if filter.At.IsZero() {
+		filter.At = time.Now()
+	}
+
+	schedules, err := s.db.GetSchedules(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (s *scheduleService) GetSchedule(id int) (models.Schedule, error) {
+	schedule, err := s.db.GetSchedule(id)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// CreateSchedule creates a schedule version, using the default flight capacity, launch time and provider when none is given.
+// The version is valid from today unless valid_from is given.
+func (s *scheduleService) CreateSchedule(request models.ScheduleRequest) (models.Schedule, error) {
+	request, err := s.withScheduleDefaults(request)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	schedule, err := s.db.InsertSchedule(request)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// UpdateSchedule corrects a schedule version in place, using the default flight capacity and launch time when none is given,
+// and the validity start and provider of the version. A version that already applies only accepts a new valid_to,
+// not before today, since earlier bookings were placed on it. To change a schedule from a date on while keeping
+// the history, close the current version with valid_to and create a new version instead.
+func (s *scheduleService) UpdateSchedule(id int, request models.ScheduleRequest) (models.Schedule, error) {
+	existing, err := s.db.GetSchedule(id)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+	if request.ValidFrom.IsZero() {
+		request.ValidFrom = existing.ValidFrom
+	}
+	if request.Provider == "" {
+		request.Provider = existing.Provider
+	}
+	request, err = s.withScheduleDefaults(request)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+	if isApplied(existing) {
+		if err := checkClosing(existing, request); err != nil {
+			return models.Schedule{}, err
+		}
+	}
+
+	schedule, err := s.db.UpdateSchedule(id, request)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule deletes a schedule version that does not apply yet. Versions that already apply are kept
+// for history, see ErrScheduleApplied.
+func (s *scheduleService) DeleteSchedule(id int) error {
+	existing, err := s.db.GetSchedule(id)
+	if err != nil {
+		return err
+	}
+	if isApplied(existing) {
+		return ErrScheduleApplied
+	}
+
+	err = s.db.DeleteSchedule(id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// isApplied reports whether the schedule version applies from today or an earlier day, so bookings may rely on it.
+func isApplied(schedule models.Schedule) bool {
+	return !schedule.ValidFrom.After(today())
+}
+
+// checkClosing checks that the request only changes the valid_to of the schedule version, to today or later.
+func checkClosing(existing models.Schedule, request models.ScheduleRequest) error {
+	if request.LaunchpadID != existing.LaunchpadID || request.Provider != existing.Provider ||
+		request.DestinationID != existing.Destination || *request.DayOfWeek != existing.DayOfWeek ||
+		request.LaunchTime != existing.LaunchTime || request.Capacity != existing.Capacity ||
+		!sameDay(request.ValidFrom, existing.ValidFrom) {
+		return fmt.Errorf("%w: only valid_to can be changed", ErrScheduleApplied)
+	}
+	if request.ValidTo != nil && request.ValidTo.Before(today()) {
+		return fmt.Errorf("%w: valid_to must not be before today", ErrScheduleApplied)
+	}
+
+	return nil
+}
+
+// today returns the start of the current UTC day, the day flights and schedule versions are dated by.
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// sameDay reports whether both times fall on the same UTC day.
+func sameDay(a, b time.Time) bool {
+	return a.UTC().Format(time.DateOnly) == b.UTC().Format(time.DateOnly)
+}
+
+func (s *scheduleService) GetScheduleOverrides(filter models.ScheduleOverrideFilter) ([]models.ScheduleOverride, error) {
+	overrides, err := s.db.GetScheduleOverrides(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+// CreateScheduleOverride creates or replaces the override of a launchpad on a date and launch time,
+// using the replace mode, the default flight capacity and launch time when none is given.
+func (s *scheduleService) CreateScheduleOverride(request models.ScheduleOverrideRequest) (models.ScheduleOverride, error) {
+	if request.Mode == "" {
+		request.Mode = models.OverrideReplace
+	}
+	if request.Mode == models.OverrideAdd && request.DestinationID == nil {
+		return models.ScheduleOverride{}, ErrInvalidOverride
+	}
+	if request.Capacity == 0 {
+		request.Capacity = models.DefaultFlightCapacity
+	}
+	if request.LaunchTime == "" {
+		request.LaunchTime = models.DefaultLaunchTime
+	}
+	launchTime, err := normalizeLaunchTime(request.LaunchTime)
+	if err != nil {
+		return models.ScheduleOverride{}, err
+	}
+	request.LaunchTime = launchTime
+
+	override, err := s.db.InsertScheduleOverride(request)
+	if err != nil {
+		return models.ScheduleOverride{}, err
+	}
+
+	return override, nil
+}
+
+func (s *scheduleService) DeleteScheduleOverride(id int) error {
+	err := s.db.DeleteScheduleOverride(id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// withScheduleDefaults fills the default capacity, launch time, validity and provider of a schedule request
+// and checks its launch time, validity period and provider.
+func (s *scheduleService) withScheduleDefaults(request models.ScheduleRequest) (models.ScheduleRequest, error) {
+	if request.Capacity == 0 {
+		request.Capacity = models.DefaultFlightCapacity
+	}
+	if request.LaunchTime == "" {
+		request.LaunchTime = models.DefaultLaunchTime
+	}
+	launchTime, err := normalizeLaunchTime(request.LaunchTime)
+	if err != nil {
+		return request, err
+	}
+	request.LaunchTime = launchTime
+	if request.ValidFrom.IsZero() {
+		request.ValidFrom = time.Now()
+	}
+	if request.ValidTo != nil && !request.ValidTo.After(request.ValidFrom) {
+		return request, fmt.Errorf("%w: valid_to must be after valid_from", ErrInvalidDateRange)
+	}
+	if request.Provider == "" {
+		request.Provider = s.providers.Default()
+	}
+	if _, err := s.providers.Get(request.Provider); err != nil {
+		return request, err
+	}
+
+	return request, nil
+}
+
+// normalizeLaunchTime checks that the launch time is a valid HH:MM time and returns it zero-padded,
+// the format launch times are read from the database in.
+func normalizeLaunchTime(launchTime string) (string, error) {
+	parsed, err := time.Parse("15:04", launchTime)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLaunchTime, launchTime)
+	}
+
+	return parsed.Format("15:04"), nil
+}
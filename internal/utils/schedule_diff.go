@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// ScheduleKey identifies a schedule by its launchpad, day of the week and launch time.
+type ScheduleKey struct {
+	LaunchpadID string
+	DayOfWeek   time.Weekday
+	LaunchTime  string
+}
+
+// ScheduleChange holds the current and the proposed version of a changed schedule.
+type ScheduleChange struct {
+	Current  models.Schedule
+	Proposed models.Schedule
+}
+
+// ScheduleDiff describes the differences between the current and the proposed schedules.
+type ScheduleDiff struct {
+	Added   []models.Schedule
+	Changed []ScheduleChange
+	Removed []models.Schedule
+}
+
+// Empty reports whether the proposed schedules are the same as the current ones.
+func (d ScheduleDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// KeySchedules indexes the schedules by launchpad, day of the week and launch time.
+func KeySchedules(schedules []models.Schedule) map[ScheduleKey]models.Schedule {
+	keyed := make(map[ScheduleKey]models.Schedule, len(schedules))
+	for _, schedule := range schedules {
+		keyed[ScheduleKey{LaunchpadID: schedule.LaunchpadID, DayOfWeek: schedule.DayOfWeek, LaunchTime: schedule.LaunchTime}] = schedule
+	}
+
+	return keyed
+}
+
+// DiffSchedules compares the current schedules with the proposed ones. A schedule is changed
+// when its destination or capacity differs. The result is ordered by launchpad, day of the week and launch time.
+func DiffSchedules(current, proposed []models.Schedule) ScheduleDiff {
+	currentByKey := KeySchedules(current)
+	proposedByKey := KeySchedules(proposed)
+
+	var diff ScheduleDiff
+	for key, schedule := range proposedByKey {
+		existing, ok := currentByKey[key]
+		if !ok {
+			diff.Added = append(diff.Added, schedule)
+			continue
+		}
+		if existing.Destination != schedule.Destination || existing.Capacity != schedule.Capacity {
+			diff.Changed = append(diff.Changed, ScheduleChange{Current: existing, Proposed: schedule})
+		}
+	}
+	for key, schedule := range currentByKey {
+		if _, ok := proposedByKey[key]; !ok {
+			diff.Removed = append(diff.Removed, schedule)
+		}
+	}
+
+	sortSchedules(diff.Added)
+	sortSchedules(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return scheduleLess(diff.Changed[i].Current, diff.Changed[j].Current)
+	})
+
+	return diff
+}
+
+// AffectedBookings returns the bookings whose launchpad no longer flies to their destination
+// at the launch time on the weekday of the launch under the proposed schedules.
+func AffectedBookings(bookings []models.Booking, proposed []models.Schedule) []models.Booking {
+	proposedByKey := KeySchedules(proposed)
+
+	var affected []models.Booking
+	for _, booking := range bookings {
+		key := ScheduleKey{LaunchpadID: booking.LaunchpadID, DayOfWeek: booking.LaunchDate.Weekday(), LaunchTime: booking.LaunchTime}
+		schedule, ok := proposedByKey[key]
+		if !ok || schedule.Destination != booking.DestinationID {
+			affected = append(affected, booking)
+		}
+	}
+
+	return affected
+}
+
+// sortSchedules sorts the schedules by launchpad, day of the week and launch time.
+func sortSchedules(schedules []models.Schedule) {
+	sort.Slice(schedules, func(i, j int) bool {
+		return scheduleLess(schedules[i], schedules[j])
+	})
+}
+
+func scheduleLess(a, b models.Schedule) bool {
+	if a.LaunchpadID != b.LaunchpadID {
+		return a.LaunchpadID < b.LaunchpadID
+	}
+
+	if a.DayOfWeek != b.DayOfWeek {
+		return a.DayOfWeek < b.DayOfWeek
+	}
+
+	return a.LaunchTime < b.LaunchTime
+}
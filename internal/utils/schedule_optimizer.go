@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+// OptimizeSchedule assigns destinations to the launchpads for each day of the week so that:
+//   - every destination is reachable every day when there are at least as many launchpads as destinations,
+//     and otherwise every destination is reachable at least once a week with no destination twice on a day,
+//   - each launchpad rotates through the destinations instead of repeating the same ones,
+//   - the departures left after coverage go to destinations in proportion to their demand.
+//
+// The demand maps destinations to their number of bookings, an empty map spreads departures evenly.
+// The result only depends on the launchpad IDs and the demand.
+func OptimizeSchedule(availableLaunchpads []models.Filtered, demand map[models.Destination]int) []models.Schedule {
+	daysOfWeek := []time.Weekday{
+		time.Sunday,
+		time.Monday,
+		time.Tuesday,
+		time.Wednesday,
+		time.Thursday,
+		time.Friday,
+		time.Saturday,
+	}
+
+	availableDestinations := []models.Destination{
+		models.Mars,
+		models.Moon,
+		models.Pluto,
+		models.AsteroidBelt,
+		models.Europa,
+		models.Titan,
+		models.Ganymede,
+	}
+
+	launchpads := make([]models.Filtered, len(availableLaunchpads))
+	copy(launchpads, availableLaunchpads)
+	sort.Slice(launchpads, func(i, j int) bool {
+		return launchpads[i].ID < launchpads[j].ID
+	})
+	if len(launchpads) == 0 {
+		return []models.Schedule{}
+	}
+
+	quotas := destinationQuotas(availableDestinations, len(launchpads)*len(daysOfWeek), len(launchpads), demand)
+
+	// Spread the departures of each destination over consecutive days, so a destination with
+	// up to seven departures never flies twice on the same day. Each day gets one departure per launchpad.
+	order := make([]models.Destination, len(availableDestinations))
+	copy(order, availableDestinations)
+	sort.SliceStable(order, func(i, j int) bool {
+		return quotas[order[i]] > quotas[order[j]]
+	})
+	daily := make([][]models.Destination, len(daysOfWeek))
+	slot := 0
+	for _, destination := range order {
+		for i := 0; i < quotas[destination]; i++ {
+			day := slot % len(daysOfWeek)
+			daily[day] = append(daily[day], destination)
+			slot++
+		}
+	}
+
+	// Rotate the destinations of each day across the launchpads, so every launchpad
+	// flies to a different destination on the following day.
+	schedule := make([]models.Schedule, 0, slot)
+	for p, launchpad := range launchpads {
+		for d, day := range daysOfWeek {
+			destinations := daily[d]
+			schedule = append(schedule, models.Schedule{
+				ID:          uint(len(schedule) + 1),
+				LaunchpadID: launchpad.ID,
+				Destination: destinations[(p+d)%len(destinations)],
+				DayOfWeek:   day,
+			})
+		}
+	}
+
+	return schedule
+}
+
+// destinationQuotas splits the weekly departures between the destinations. Every destination first gets
+// its coverage minimum, seven departures when there is a launchpad for each destination and one otherwise.
+// The remaining departures are handed out one by one to the destination with the highest demand per
+// departure (the D'Hondt method), without exceeding one departure a day when launchpads are scarce.
+func destinationQuotas(destinations []models.Destination, departures, launchpads int, demand map[models.Destination]int) map[models.Destination]int {
+	days := departures / launchpads
+	minimum, maximum := 1, days
+	if launchpads >= len(destinations) {
+		minimum, maximum = days, departures
+	}
+
+	quotas := make(map[models.Destination]int, len(destinations))
+	remaining := departures
+	for _, destination := range destinations {
+		quotas[destination] = minimum
+		remaining -= minimum
+	}
+
+	for ; remaining > 0; remaining-- {
+		var best models.Destination
+		bestScore := -1.0
+		for _, destination := range destinations {
+			if quotas[destination] >= maximum {
+				continue
+			}
+			// Smooth the demand, so destinations without bookings still get departures.
+			score := float64(demand[destination]+1) / float64(quotas[destination]+1)
+			if score > bestScore {
+				best, bestScore = destination, score
+			}
+		}
+		quotas[best]++
+	}
+
+	return quotas
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klemis/go-spaceflight-booking-api/models"
+)
+
+var destinations = []models.Destination{
+	models.Mars,
+	models.Moon,
+	models.Pluto,
+	models.AsteroidBelt,
+	models.Europa,
+	models.Titan,
+	models.Ganymede,
+}
+
+// launchpads returns n launchpads with distinct IDs.
+func launchpads(n int) []models.Filtered {
+	pads := make([]models.Filtered, n)
+	for i := range pads {
+		pads[i] = models.Filtered{ID: fmt.Sprintf("pad-%02d", i)}
+	}
+
+	return pads
+}
+
+// destinationsByDay groups the departures of the schedule by day of the week.
+func destinationsByDay(schedule []models.Schedule) map[time.Weekday][]models.Destination {
+	days := make(map[time.Weekday][]models.Destination)
+	for _, departure := range schedule {
+		days[departure.DayOfWeek] = append(days[departure.DayOfWeek], departure.Destination)
+	}
+
+	return days
+}
+
+func TestOptimizeScheduleCoversEveryDestinationDaily(t *testing.T) {
+	for _, n := range []int{7, 8, 12, 20} {
+		t.Run(fmt.Sprintf("%d launchpads", n), func(t *testing.T) {
+			schedule := OptimizeSchedule(launchpads(n), map[models.Destination]int{models.Mars: 40, models.Moon: 3})
+			if len(schedule) != n*7 {
+				t.Fatalf("got %d departures, want %d", len(schedule), n*7)
+			}
+
+			days := destinationsByDay(schedule)
+			for day := time.Sunday; day <= time.Saturday; day++ {
+				if len(days[day]) != n {
+					t.Errorf("%s has %d departures, want %d", day, len(days[day]), n)
+				}
+				for _, destination := range destinations {
+					if !containsDestination(days[day], destination) {
+						t.Errorf("%s has no departure to %s", day, String(destination))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOptimizeScheduleCoversEveryDestinationWeekly(t *testing.T) {
+	for n := 1; n < 7; n++ {
+		t.Run(fmt.Sprintf("%d launchpads", n), func(t *testing.T) {
+			schedule := OptimizeSchedule(launchpads(n), map[models.Destination]int{models.Mars: 40})
+			if len(schedule) != n*7 {
+				t.Fatalf("got %d departures, want %d", len(schedule), n*7)
+			}
+
+			weekly := make(map[models.Destination]int)
+			for day, daily := range destinationsByDay(schedule) {
+				seen := make(map[models.Destination]bool)
+				for _, destination := range daily {
+					if seen[destination] {
+						t.Errorf("%s has two departures to %s", day, String(destination))
+					}
+					seen[destination] = true
+					weekly[destination]++
+				}
+			}
+			for _, destination := range destinations {
+				if weekly[destination] == 0 {
+					t.Errorf("no departure to %s in the week", String(destination))
+				}
+			}
+		})
+	}
+}
+
+func TestOptimizeScheduleIsDeterministic(t *testing.T) {
+	demand := map[models.Destination]int{models.Mars: 12, models.Titan: 5, models.Europa: 5}
+	pads := launchpads(9)
+	reversed := make([]models.Filtered, len(pads))
+	for i, pad := range pads {
+		reversed[len(pads)-1-i] = pad
+	}
+
+	want := OptimizeSchedule(pads, demand)
+	for i := 0; i < 5; i++ {
+		if got := OptimizeSchedule(pads, demand); !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d differs from the first run", i)
+		}
+	}
+	if got := OptimizeSchedule(reversed, demand); !reflect.DeepEqual(got, want) {
+		t.Error("schedule depends on the order of the launchpads")
+	}
+}
+
+func TestOptimizeScheduleWithoutLaunchpads(t *testing.T) {
+	if schedule := OptimizeSchedule(nil, nil); len(schedule) != 0 {
+		t.Errorf("got %d departures, want none", len(schedule))
+	}
+}
+
+func TestDestinationQuotasFollowDemand(t *testing.T) {
+	demand := map[models.Destination]int{models.Mars: 60, models.Moon: 30, models.Pluto: 10}
+	quotas := destinationQuotas(destinations, 10*7, 10, demand)
+
+	total := 0
+	for _, destination := range destinations {
+		if quotas[destination] < 7 {
+			t.Errorf("%s has %d departures, want at least one a day", String(destination), quotas[destination])
+		}
+		total += quotas[destination]
+	}
+	if total != 70 {
+		t.Errorf("quotas add up to %d departures, want 70", total)
+	}
+	if !(quotas[models.Mars] > quotas[models.Moon] && quotas[models.Moon] > quotas[models.Pluto]) {
+		t.Errorf("quotas do not follow demand: Mars %d, Moon %d, Pluto %d", quotas[models.Mars], quotas[models.Moon], quotas[models.Pluto])
+	}
+	if quotas[models.Pluto] < quotas[models.Titan] {
+		t.Errorf("Pluto has %d departures, fewer than Titan without bookings (%d)", quotas[models.Pluto], quotas[models.Titan])
+	}
+}
+
+func TestDestinationQuotasWithScarceLaunchpads(t *testing.T) {
+	quotas := destinationQuotas(destinations, 3*7, 3, map[models.Destination]int{models.Mars: 1000})
+
+	total := 0
+	for _, destination := range destinations {
+		if quotas[destination] < 1 || quotas[destination] > 7 {
+			t.Errorf("%s has %d departures, want between 1 and 7", String(destination), quotas[destination])
+		}
+		total += quotas[destination]
+	}
+	if total != 21 {
+		t.Errorf("quotas add up to %d departures, want 21", total)
+	}
+	if quotas[models.Mars] != 7 {
+		t.Errorf("Mars has %d departures, want one a day", quotas[models.Mars])
+	}
+}
+
+func TestDestinationQuotasWithoutDemand(t *testing.T) {
+	quotas := destinationQuotas(destinations, 14*7, 14, nil)
+	for _, destination := range destinations {
+		if quotas[destination] != 14 {
+			t.Errorf("%s has %d departures, want 14", String(destination), quotas[destination])
+		}
+	}
+}
+
+func containsDestination(list []models.Destination, destination models.Destination) bool {
+	for _, d := range list {
+		if d == destination {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/klemis/go-spaceflight-booking-api/models"
@@ -31,7 +33,11 @@ func GetRangeQueryValues(dateTime time.Time) (gte, lt string) {
 	return
 }
 
-func GenerateSchedule(availableLaunchpads []models.Filtered) []models.Schedule {
+// GenerateSchedule assigns every launchpad a different destination for each day of the week.
+// The assignment is deterministic: each launchpad's destinations are shuffled with a generator
+// seeded from the seed and the launchpad ID, so the same launchpad gets the same week on every run
+// and newly active launchpads do not reshuffle the existing ones.
+func GenerateSchedule(availableLaunchpads []models.Filtered, seed int64) []models.Schedule {
 	daysOfWeek := []time.Weekday{
 		time.Sunday,
 		time.Monday,
@@ -52,12 +58,18 @@ func GenerateSchedule(availableLaunchpads []models.Filtered) []models.Schedule {
 		models.Ganymede,
 	}
 
-	// Create a new random number generator with a source seeded by the current time
-	source := rand.NewSource(time.Now().UnixNano())
-	rng := rand.New(source)
+	// Sort the launchpads, so the schedule order does not depend on the order returned by the API.
+	launchpads := make([]models.Filtered, len(availableLaunchpads))
+	copy(launchpads, availableLaunchpads)
+	sort.Slice(launchpads, func(i, j int) bool {
+		return launchpads[i].ID < launchpads[j].ID
+	})
 
 	schedule := make([]models.Schedule, 0)
-	for _, launchpad := range availableLaunchpads {
+	for _, launchpad := range launchpads {
+		// Create a random number generator seeded by the seed and the launchpad ID
+		rng := rand.New(rand.NewSource(launchpadSeed(seed, launchpad.ID)))
+
 		// Create a copy of the destinations to shuffle
 		destinationsCopy := make([]models.Destination, len(availableDestinations))
 		copy(destinationsCopy, availableDestinations)
@@ -77,6 +89,15 @@ func GenerateSchedule(availableLaunchpads []models.Filtered) []models.Schedule {
 	return schedule
 }
 
+// launchpadSeed derives the seed of a launchpad's generator from the schedule seed and the launchpad ID.
+func launchpadSeed(seed int64, launchpadID string) int64 {
+	hash := fnv.New64a()
+	// Writing to a hash never returns an error.
+	_, _ = hash.Write([]byte(launchpadID))
+
+	return seed ^ int64(hash.Sum64())
+}
+
 // shuffleDestinations shuffles the slice of destinations in place.
 func shuffleDestinations(destinations []models.Destination, rng *rand.Rand) {
 	rng.Shuffle(len(destinations), func(i, j int) {
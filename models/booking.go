@@ -2,15 +2,35 @@ package models
 
 import "time"
 
+// BookingStatus represents the lifecycle state of a booking.
+type BookingStatus string
+
+const (
+	BookingPending   BookingStatus = "pending"
+	BookingConfirmed BookingStatus = "confirmed"
+	BookingCancelled BookingStatus = "cancelled"
+	BookingFlown     BookingStatus = "flown"
+)
+
 type Booking struct {
-	ID            uint        `json:"id"`
-	FirstName     string      `json:"first_name"`
-	LastName      string      `json:"last_name"`
-	Gender        string      `json:"gender"`
-	Birthday      time.Time   `json:"birthday"`
-	LaunchpadID   string      `json:"launchpad_id"`
-	DestinationID Destination `json:"destination_id"`
-	LaunchDate    time.Time   `json:"launch_date"`
+	ID            uint          `json:"id"`
+	FlightID      uint          `json:"flight_id"`
+	FirstName     string        `json:"first_name"`
+	LastName      string        `json:"last_name"`
+	Gender        string        `json:"gender"`
+	Birthday      time.Time     `json:"birthday"`
+	LaunchpadID   string        `json:"launchpad_id"`
+	Provider      string        `json:"provider"`
+	DestinationID Destination   `json:"destination_id"`
+	LaunchDate    time.Time     `json:"launch_date"`
+	LaunchTime    string        `json:"launch_time"`
+	Status        BookingStatus `json:"status"`
+	CancelReason  string        `json:"cancellation_reason,omitempty"`
+}
+
+// CancelRequest represents the optional body of a booking cancellation.
+type CancelRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
 }
 
 type BookingRequest struct {
@@ -20,4 +40,28 @@ type BookingRequest struct {
 	Birthday      time.Time   `json:"birthday" validate:"required"`
 	DestinationID Destination `json:"destination_id" validate:"required,gte=1,lte=7"`
 	LaunchDate    time.Time   `json:"launch_date" validate:"required"`
+	LaunchTime    string      `json:"launch_time"`
+	LaunchpadID   string      `json:"launchpad_id" validate:"omitempty,max=255"`
+	Provider      string      `json:"provider" validate:"omitempty,max=50"`
+}
+
+// BookingFilter holds the pagination, filtering and sorting parameters for listing bookings.
+type BookingFilter struct {
+	Limit          int           `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Cursor         string        `form:"cursor"`
+	DestinationID  Destination   `form:"destination_id" validate:"omitempty,gte=1,lte=7"`
+	LaunchpadID    string        `form:"launchpad_id"`
+	Provider       string        `form:"provider"`
+	Status         BookingStatus `form:"status" validate:"omitempty,oneof=pending confirmed cancelled flown"`
+	LaunchDateFrom time.Time     `form:"launch_date_from" time_format:"2006-01-02"`
+	LaunchDateTo   time.Time     `form:"launch_date_to" time_format:"2006-01-02"`
+	Name           string        `form:"name" validate:"omitempty,max=100"`
+	Sort           string        `form:"sort" validate:"omitempty,oneof=id -id launch_date -launch_date"`
+}
+
+// BookingPage represents a single page of bookings.
+type BookingPage struct {
+	Bookings   []Booking `json:"bookings"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	TotalCount int       `json:"total_count"`
 }
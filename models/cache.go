@@ -0,0 +1,16 @@
+package models
+
+// CacheCounters represents the lookups of one kind of cached call.
+type CacheCounters struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	Errors   uint64  `json:"errors"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// LaunchCacheStats represents the cache hit metrics of the SpaceX launch and launchpad lookups.
+type LaunchCacheStats struct {
+	TTLSeconds int           `json:"ttl_seconds"`
+	Launches   CacheCounters `json:"launches"`
+	Launchpads CacheCounters `json:"launchpads"`
+}
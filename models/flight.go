@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// FlightStatus represents the state of a flight.
+type FlightStatus string
+
+const (
+	FlightScheduled FlightStatus = "scheduled"
+	FlightCancelled FlightStatus = "cancelled"
+	FlightCompleted FlightStatus = "completed"
+)
+
+// Flight represents a single departure from a launchpad to a destination at a time of a day.
+// Flights are materialized from the weekly schedules.
+type Flight struct {
+	ID            uint         `json:"id"`
+	LaunchpadID   string       `json:"launchpad_id"`
+	Provider      string       `json:"provider"`
+	DestinationID Destination  `json:"destination_id"`
+	LaunchDate    time.Time    `json:"launch_date"`
+	LaunchTime    string       `json:"launch_time"`
+	Status        FlightStatus `json:"status"`
+	Capacity      int          `json:"capacity"`
+	Booked        int          `json:"booked"`
+	Remaining     int          `json:"remaining"`
+}
+
+// FlightFilter holds the filtering parameters for listing flights.
+type FlightFilter struct {
+	Limit          int          `form:"limit" validate:"omitempty,gte=1,lte=500"`
+	DestinationID  Destination  `form:"destination_id" validate:"omitempty,gte=1,lte=7"`
+	LaunchpadID    string       `form:"launchpad_id"`
+	Provider       string       `form:"provider"`
+	Status         FlightStatus `form:"status" validate:"omitempty,oneof=scheduled cancelled completed"`
+	LaunchDateFrom time.Time    `form:"launch_date_from" time_format:"2006-01-02"`
+	LaunchDateTo   time.Time    `form:"launch_date_to" time_format:"2006-01-02"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Launchpad represents the details of a launchpad.
 type Launchpad struct {
 	Images struct {
@@ -21,12 +23,48 @@ type Launchpad struct {
 	ID              string   `json:"id"`
 }
 
+// LaunchpadSummary represents a launchpad synchronized from the SpaceX API.
+type LaunchpadSummary struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Locality string    `json:"locality"`
+	Timezone string    `json:"timezone"`
+	Status   string    `json:"status"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// LaunchpadFilter holds the filtering parameters for listing launchpads.
+type LaunchpadFilter struct {
+	Status string `form:"status" validate:"omitempty,max=50"`
+}
+
+// Launch represents a SpaceX launch, blocking its launchpad on the day of the launch.
+type Launch struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	DateUTC     time.Time `json:"date_utc"`
+	Upcoming    bool      `json:"upcoming"`
+	LaunchpadID string    `json:"launchpad"`
+}
+
 // Filtered represents the id of the resource.
 type Filtered struct {
 	ID string `json:"id"`
 }
 
+// PageInfo represents the paging metadata of a response of the SpaceX query endpoints.
+type PageInfo struct {
+	TotalDocs   int  `json:"totalDocs"`
+	Limit       int  `json:"limit"`
+	Page        int  `json:"page"`
+	TotalPages  int  `json:"totalPages"`
+	HasNextPage bool `json:"hasNextPage"`
+	NextPage    *int `json:"nextPage"`
+}
+
 // FilteredResponse represents the response containing a list of filtered ids.
+// When the documents of several pages are combined, the paging metadata is the one of the last fetched page.
 type FilteredResponse struct {
 	Docs []Filtered `json:"docs"`
+	PageInfo
 }
@@ -6,10 +6,15 @@ type PopulateOption struct {
 	Select map[string]int `json:"select"`
 }
 
-// Options specifies additional query options.
+// Options specifies additional query options. Sort lists the fields to order by, descending when prefixed
+// with "-". Pagination false returns every matching document at once.
 type Options struct {
-	Select   map[string]int   `json:"select"`
-	Populate []PopulateOption `json:"populate"`
+	Select     map[string]int   `json:"select"`
+	Populate   []PopulateOption `json:"populate"`
+	Sort       string           `json:"sort,omitempty"`
+	Limit      int              `json:"limit,omitempty"`
+	Page       int              `json:"page,omitempty"`
+	Pagination *bool            `json:"pagination,omitempty"`
 }
 
 type RequestBody struct {
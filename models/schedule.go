@@ -25,11 +25,133 @@ var DestinationStrings = map[Destination]string{
 	Ganymede:     "Ganymede",
 }
 
+// DefaultFlightCapacity is the number of seats of a flight when the schedule does not define it.
+const DefaultFlightCapacity = 10
+
+// DefaultLaunchTime is the UTC launch time (HH:MM) of a departure when the schedule does not define it.
+const DefaultLaunchTime = "12:00"
+
 type Schedule struct {
-	ID          uint
-	LaunchpadID string
-	Destination Destination
-	DayOfWeek   time.Weekday
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID          uint         `json:"id"`
+	LaunchpadID string       `json:"launchpad_id"`
+	Provider    string       `json:"provider"`
+	Destination Destination  `json:"destination_id"`
+	DayOfWeek   time.Weekday `json:"day_of_week"`
+	LaunchTime  string       `json:"launch_time"`
+	Capacity    int          `json:"capacity"`
+	ValidFrom   time.Time    `json:"valid_from"`
+	ValidTo     *time.Time   `json:"valid_to"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ScheduleRequest represents the body used to create or update a schedule.
+type ScheduleRequest struct {
+	LaunchpadID   string        `json:"launchpad_id" validate:"required,max=255"`
+	Provider      string        `json:"provider" validate:"omitempty,max=50"`
+	DestinationID Destination   `json:"destination_id" validate:"required,gte=1,lte=7"`
+	DayOfWeek     *time.Weekday `json:"day_of_week" validate:"required,gte=0,lte=6"`
+	LaunchTime    string        `json:"launch_time"`
+	Capacity      int           `json:"capacity" validate:"omitempty,gte=1"`
+	ValidFrom     time.Time     `json:"valid_from"`
+	ValidTo       *time.Time    `json:"valid_to"`
+}
+
+// ScheduleFilter holds the filtering parameters for listing schedules.
+type ScheduleFilter struct {
+	LaunchpadID   string        `form:"launchpad_id"`
+	Provider      string        `form:"provider"`
+	DestinationID Destination   `form:"destination_id" validate:"omitempty,gte=1,lte=7"`
+	DayOfWeek     *time.Weekday `form:"day_of_week" validate:"omitempty,gte=0,lte=6"`
+	At            time.Time     `form:"at" time_format:"2006-01-02"`
+	History       bool          `form:"history"`
+}
+
+// SeatAvailability represents the seats of a single flight.
+type SeatAvailability struct {
+	LaunchpadID   string      `json:"launchpad_id"`
+	Provider      string      `json:"provider"`
+	DestinationID Destination `json:"destination_id"`
+	LaunchDate    time.Time   `json:"launch_date"`
+	LaunchTime    string      `json:"launch_time"`
+	Capacity      int         `json:"capacity"`
+	Booked        int         `json:"booked"`
+	Remaining     int         `json:"remaining"`
+}
+
+// Departure is a scheduled departure slot of a launchpad on a day, whether or not its flight was created yet.
+type Departure struct {
+	LaunchpadID   string
+	Provider      string
+	DestinationID Destination
+	LaunchDate    time.Time
+	LaunchTime    string
+	Capacity      int
+	Booked        int
+	Remaining     int
+}
+
+// SeatAvailabilityRequest holds the query parameters for the seat availability lookup.
+type SeatAvailabilityRequest struct {
+	DestinationID Destination `form:"destination_id" validate:"required,gte=1,lte=7"`
+	LaunchDate    time.Time   `form:"launch_date" time_format:"2006-01-02" validate:"required"`
+}
+
+// AvailabilityRequest holds the query parameters for searching bookable dates.
+type AvailabilityRequest struct {
+	DestinationID Destination `form:"destination_id" validate:"required,gte=1,lte=7"`
+	From          time.Time   `form:"from" time_format:"2006-01-02" validate:"required"`
+	To            time.Time   `form:"to" time_format:"2006-01-02" validate:"required"`
+}
+
+// AvailableFlight represents a bookable departure to a destination.
+type AvailableFlight struct {
+	LaunchDate    time.Time   `json:"launch_date"`
+	LaunchTime    string      `json:"launch_time"`
+	LaunchpadID   string      `json:"launchpad_id"`
+	Provider      string      `json:"provider"`
+	DestinationID Destination `json:"destination_id"`
+	Remaining     int         `json:"remaining"`
+}
+
+// OverrideMode decides how a schedule override changes the weekly departures of its launchpad on its date.
+type OverrideMode string
+
+const (
+	// OverrideReplace replaces every weekly departure of the launchpad with the departures of the replace overrides.
+	OverrideReplace OverrideMode = "replace"
+	// OverrideAdd adds a departure and keeps the weekly ones, except the one at the same launch time.
+	OverrideAdd OverrideMode = "add"
+)
+
+// ScheduleOverride changes the weekly schedule of a launchpad on a single date, see OverrideMode.
+// A replace override without a destination closes the launchpad for the day.
+type ScheduleOverride struct {
+	ID            uint         `json:"id"`
+	LaunchpadID   string       `json:"launchpad_id"`
+	Date          time.Time    `json:"date"`
+	Mode          OverrideMode `json:"mode"`
+	DestinationID *Destination `json:"destination_id"`
+	LaunchTime    string       `json:"launch_time"`
+	Capacity      int          `json:"capacity"`
+	Reason        string       `json:"reason,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// ScheduleOverrideRequest represents the body used to create or replace a schedule override.
+type ScheduleOverrideRequest struct {
+	LaunchpadID   string       `json:"launchpad_id" validate:"required,max=255"`
+	Date          time.Time    `json:"date" validate:"required"`
+	Mode          OverrideMode `json:"mode" validate:"omitempty,oneof=replace add"`
+	DestinationID *Destination `json:"destination_id" validate:"omitempty,gte=1,lte=7"`
+	LaunchTime    string       `json:"launch_time"`
+	Capacity      int          `json:"capacity" validate:"omitempty,gte=1"`
+	Reason        string       `json:"reason" validate:"max=255"`
+}
+
+// ScheduleOverrideFilter holds the filtering parameters for listing schedule overrides.
+type ScheduleOverrideFilter struct {
+	LaunchpadID string    `form:"launchpad_id"`
+	From        time.Time `form:"from" time_format:"2006-01-02"`
+	To          time.Time `form:"to" time_format:"2006-01-02"`
 }